@@ -1,5 +1,9 @@
 package goga
 
+import (
+	"sort"
+)
+
 var (
 	actorIdGen = ActorId(0)
 )
@@ -9,18 +13,154 @@ var (
 type ActorId uint64
 
 // A basic actor, having a unique ID.
+// Actors can optionally have a name and tags, set them using the engine
+// (like SetActorName()) to be able to find actors by them.
 // Use NewActor() to create new actors.
 type Actor struct {
-	id ActorId
+	id   ActorId
+	name string
+	tags map[string]bool
 }
 
 // Creates a new basic actor with unique ID.
 func NewActor() *Actor {
 	actorIdGen++
-	return &Actor{actorIdGen}
+	return &Actor{id: actorIdGen}
 }
 
 // Returns the ID of actor.
 func (a *Actor) GetId() ActorId {
 	return a.id
 }
+
+// Returns the name of actor or an empty string if not set.
+func (a *Actor) GetActorName() string {
+	return a.name
+}
+
+// Returns true if the actor has given tag.
+func (a *Actor) HasTag(tag string) bool {
+	return a.tags[tag]
+}
+
+// Returns the tags of actor in alphabetical order.
+func (a *Actor) GetTags() []string {
+	tags := make([]string, 0, len(a.tags))
+
+	for tag := range a.tags {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// Sets the name of an actor, which must be unique.
+// An empty name removes the name.
+// Returns false if the name is used by another actor.
+func SetActorName(actor *Actor, name string) bool {
+	return defaultEngine.SetActorName(actor, name)
+}
+
+// Sets the name of an actor, which must be unique.
+// See SetActorName() for details.
+func (e *Engine) SetActorName(actor *Actor, name string) bool {
+	if other, ok := e.actorNames[name]; ok && other != actor {
+		return false
+	}
+
+	if actor.name != "" {
+		delete(e.actorNames, actor.name)
+	}
+
+	actor.name = name
+
+	if name != "" {
+		e.actorNames[name] = actor
+	}
+
+	return true
+}
+
+// Adds a tag to an actor.
+func AddActorTag(actor *Actor, tag string) {
+	defaultEngine.AddActorTag(actor, tag)
+}
+
+// Adds a tag to an actor.
+func (e *Engine) AddActorTag(actor *Actor, tag string) {
+	if actor.tags == nil {
+		actor.tags = make(map[string]bool)
+	}
+
+	actor.tags[tag] = true
+
+	if _, ok := e.actorTags[tag]; !ok {
+		e.actorTags[tag] = make(map[ActorId]*Actor)
+	}
+
+	e.actorTags[tag][actor.GetId()] = actor
+}
+
+// Removes a tag from an actor.
+// Returns false if the actor does not have the tag.
+func RemoveActorTag(actor *Actor, tag string) bool {
+	return defaultEngine.RemoveActorTag(actor, tag)
+}
+
+// Removes a tag from an actor.
+// Returns false if the actor does not have the tag.
+func (e *Engine) RemoveActorTag(actor *Actor, tag string) bool {
+	if !actor.tags[tag] {
+		return false
+	}
+
+	delete(actor.tags, tag)
+	delete(e.actorTags[tag], actor.GetId())
+
+	if len(e.actorTags[tag]) == 0 {
+		delete(e.actorTags, tag)
+	}
+
+	return true
+}
+
+// Finds and returns an actor by name, or nil if not found.
+func FindActorByName(name string) *Actor {
+	return defaultEngine.FindActorByName(name)
+}
+
+// Finds and returns an actor by name, or nil if not found.
+func (e *Engine) FindActorByName(name string) *Actor {
+	return e.actorNames[name]
+}
+
+// Returns all actors having given tag, ordered by ID.
+func FindActorsByTag(tag string) []*Actor {
+	return defaultEngine.FindActorsByTag(tag)
+}
+
+// Returns all actors having given tag, ordered by ID.
+func (e *Engine) FindActorsByTag(tag string) []*Actor {
+	actors := make([]*Actor, 0, len(e.actorTags[tag]))
+
+	for _, actor := range e.actorTags[tag] {
+		actors = append(actors, actor)
+	}
+
+	sort.Slice(actors, func(i, j int) bool {
+		return actors[i].GetId() < actors[j].GetId()
+	})
+
+	return actors
+}
+
+// Removes the name and tags of an actor from lookup.
+func (e *Engine) removeActorLookup(actor *Actor) {
+	e.SetActorName(actor, "")
+
+	for _, tag := range actor.GetTags() {
+		e.RemoveActorTag(actor, tag)
+	}
+}
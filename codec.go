@@ -0,0 +1,362 @@
+package goga
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// Names of built-in component codecs, used as keys in level files.
+	Pos2D_codec_name             = "pos2d"
+	Pos3D_codec_name             = "pos3d"
+	Tex_codec_name               = "tex"
+	KeyframeSet_codec_name       = "keyframeSet"
+	KeyframeAnimation_codec_name = "keyframeAnimation"
+	TextComponent_codec_name     = "text"
+)
+
+// A component codec converts a component type from and to JSON.
+// Register codecs for custom components using RegisterComponentCodec(),
+// to save and load them as part of a level.
+type ComponentCodec interface {
+	GetName() string
+	GetType() ComponentType
+	Encode(*Engine, interface{}) (json.RawMessage, error)
+	Decode(*Engine, json.RawMessage) (interface{}, error)
+}
+
+// Registers a component codec.
+// Returns false if a codec with the same name or type exists already.
+func RegisterComponentCodec(codec ComponentCodec) bool {
+	return defaultEngine.RegisterComponentCodec(codec)
+}
+
+// Registers a component codec.
+// Returns false if a codec with the same name or type exists already.
+func (e *Engine) RegisterComponentCodec(codec ComponentCodec) bool {
+	if e.GetComponentCodecByName(codec.GetName()) != nil || e.GetComponentCodecByType(codec.GetType()) != nil {
+		return false
+	}
+
+	e.codecs = append(e.codecs, codec)
+
+	return true
+}
+
+// Removes a component codec by name.
+// Returns false if it could not be found.
+func RemoveComponentCodec(name string) bool {
+	return defaultEngine.RemoveComponentCodec(name)
+}
+
+// Removes a component codec by name.
+// Returns false if it could not be found.
+func (e *Engine) RemoveComponentCodec(name string) bool {
+	for i, codec := range e.codecs {
+		if codec.GetName() == name {
+			e.codecs = append(e.codecs[:i], e.codecs[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the component codec for given name or nil if not found.
+func GetComponentCodecByName(name string) ComponentCodec {
+	return defaultEngine.GetComponentCodecByName(name)
+}
+
+// Returns the component codec for given name or nil if not found.
+func (e *Engine) GetComponentCodecByName(name string) ComponentCodec {
+	for _, codec := range e.codecs {
+		if codec.GetName() == name {
+			return codec
+		}
+	}
+
+	return nil
+}
+
+// Returns the component codec for given component type or nil if not found.
+func GetComponentCodecByType(ctype ComponentType) ComponentCodec {
+	return defaultEngine.GetComponentCodecByType(ctype)
+}
+
+// Returns the component codec for given component type or nil if not found.
+func (e *Engine) GetComponentCodecByType(ctype ComponentType) ComponentCodec {
+	for _, codec := range e.codecs {
+		if codec.GetType() == ctype {
+			return codec
+		}
+	}
+
+	return nil
+}
+
+// Registers codecs for built-in components.
+func (e *Engine) registerDefaultCodecs() {
+	e.RegisterComponentCodec(&Pos2DCodec{})
+	e.RegisterComponentCodec(&Pos3DCodec{})
+	e.RegisterComponentCodec(&TexCodec{})
+	e.RegisterComponentCodec(&KeyframeSetCodec{})
+	e.RegisterComponentCodec(&KeyframeAnimationCodec{})
+	e.RegisterComponentCodec(&TextComponentCodec{})
+}
+
+type jsonPos2D struct {
+	Pos      Vec2    `json:"pos"`
+	Size     Vec2    `json:"size"`
+	Scale    Vec2    `json:"scale"`
+	RotPoint Vec2    `json:"rotPoint"`
+	Rot      float64 `json:"rot"`
+	Visible  bool    `json:"visible"`
+}
+
+// Codec for Pos2D components.
+type Pos2DCodec struct{}
+
+func (c *Pos2DCodec) GetName() string {
+	return Pos2D_codec_name
+}
+
+func (c *Pos2DCodec) GetType() ComponentType {
+	return Pos2DType
+}
+
+func (c *Pos2DCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	pos := component.(*Pos2D)
+	return json.Marshal(jsonPos2D{pos.Pos, pos.Size, pos.Scale, pos.RotPoint, pos.Rot, pos.Visible})
+}
+
+func (c *Pos2DCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	pos := NewPos2D()
+	value := jsonPos2D{pos.Pos, pos.Size, pos.Scale, pos.RotPoint, pos.Rot, pos.Visible}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	pos.Pos = value.Pos
+	pos.Size = value.Size
+	pos.Scale = value.Scale
+	pos.RotPoint = value.RotPoint
+	pos.Rot = value.Rot
+	pos.Visible = value.Visible
+
+	return pos, nil
+}
+
+type jsonPos3D struct {
+	Pos      Vec3 `json:"pos"`
+	Size     Vec3 `json:"size"`
+	Scale    Vec3 `json:"scale"`
+	RotPoint Vec3 `json:"rotPoint"`
+	Rot      Vec3 `json:"rot"`
+	Visible  bool `json:"visible"`
+}
+
+// Codec for Pos3D components.
+type Pos3DCodec struct{}
+
+func (c *Pos3DCodec) GetName() string {
+	return Pos3D_codec_name
+}
+
+func (c *Pos3DCodec) GetType() ComponentType {
+	return Pos3DType
+}
+
+func (c *Pos3DCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	pos := component.(*Pos3D)
+	return json.Marshal(jsonPos3D{pos.Pos, pos.Size, pos.Scale, pos.RotPoint, pos.Rot, pos.Visible})
+}
+
+func (c *Pos3DCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	pos := NewPos3D()
+	value := jsonPos3D{pos.Pos, pos.Size, pos.Scale, pos.RotPoint, pos.Rot, pos.Visible}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	pos.Pos = value.Pos
+	pos.Size = value.Size
+	pos.Scale = value.Scale
+	pos.RotPoint = value.RotPoint
+	pos.Rot = value.Rot
+	pos.Visible = value.Visible
+
+	return pos, nil
+}
+
+// Codec for Tex components.
+// Textures are stored by resource name and resolved using GetTex() when decoded,
+// so they must be loaded before.
+type TexCodec struct{}
+
+func (c *TexCodec) GetName() string {
+	return Tex_codec_name
+}
+
+func (c *TexCodec) GetType() ComponentType {
+	return TexType
+}
+
+// Returns true, as textures are resources shared by all prefab instances.
+func (c *TexCodec) IsShared() bool {
+	return true
+}
+
+func (c *TexCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	tex := component.(*Tex)
+
+	if tex.GetName() == "" {
+		return nil, errors.New("Texture is not a named resource")
+	}
+
+	return json.Marshal(tex.GetName())
+}
+
+func (c *TexCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	var name string
+
+	if err := json.Unmarshal(data, &name); err != nil {
+		return nil, err
+	}
+
+	tex, err := e.GetTex(name)
+
+	if err != nil {
+		return nil, errors.New("Texture " + name + ": " + err.Error())
+	}
+
+	return tex, nil
+}
+
+type jsonKeyframe struct {
+	Min Vec2 `json:"min"`
+	Max Vec2 `json:"max"`
+}
+
+// Codec for KeyframeSet components.
+// The keyframes are stored by their texture coordinates.
+type KeyframeSetCodec struct{}
+
+func (c *KeyframeSetCodec) GetName() string {
+	return KeyframeSet_codec_name
+}
+
+func (c *KeyframeSetCodec) GetType() ComponentType {
+	return KeyframeSetType
+}
+
+// Returns true, so prefab instances share the keyframes and their GL buffers.
+func (c *KeyframeSetCodec) IsShared() bool {
+	return true
+}
+
+func (c *KeyframeSetCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	set := component.(*KeyframeSet)
+	frames := make([]jsonKeyframe, 0, len(set.Keyframes))
+
+	for _, frame := range set.Keyframes {
+		frames = append(frames, jsonKeyframe{frame.Min, frame.Max})
+	}
+
+	return json.Marshal(frames)
+}
+
+func (c *KeyframeSetCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	frames := make([]jsonKeyframe, 0)
+
+	if err := json.Unmarshal(data, &frames); err != nil {
+		return nil, err
+	}
+
+	set := NewKeyframeSet()
+
+	for _, frame := range frames {
+		set.Add(NewKeyframe(frame.Min, frame.Max))
+	}
+
+	return set, nil
+}
+
+type jsonKeyframeAnimation struct {
+	Start         int     `json:"start"`
+	End           int     `json:"end"`
+	Loop          bool    `json:"loop"`
+	Speed         float64 `json:"speed"`
+	Current       int     `json:"current"`
+	Interpolation float64 `json:"interpolation"`
+}
+
+// Codec for KeyframeAnimation components.
+type KeyframeAnimationCodec struct{}
+
+func (c *KeyframeAnimationCodec) GetName() string {
+	return KeyframeAnimation_codec_name
+}
+
+func (c *KeyframeAnimationCodec) GetType() ComponentType {
+	return KeyframeAnimationType
+}
+
+func (c *KeyframeAnimationCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	animation := component.(*KeyframeAnimation)
+	return json.Marshal(jsonKeyframeAnimation(*animation))
+}
+
+func (c *KeyframeAnimationCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	value := jsonKeyframeAnimation{}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	animation := KeyframeAnimation(value)
+
+	return &animation, nil
+}
+
+type jsonText struct {
+	Text  string `json:"text"`
+	Color Vec4   `json:"color"`
+}
+
+// Codec for TextComponent components.
+// Decoded texts use the font of the text renderer, which must be set before.
+type TextComponentCodec struct{}
+
+func (c *TextComponentCodec) GetName() string {
+	return TextComponent_codec_name
+}
+
+func (c *TextComponentCodec) GetType() ComponentType {
+	return TextComponentType
+}
+
+func (c *TextComponentCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	text := component.(*TextComponent)
+	return json.Marshal(jsonText{text.GetText(), text.Color})
+}
+
+func (c *TextComponentCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	value := jsonText{Color: Vec4{1, 1, 1, 1}}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	renderer, ok := e.GetSystemByName(text_renderer_name).(*TextRenderer)
+
+	if !ok || renderer.Font == nil {
+		return nil, errors.New("Text renderer has no font to create text with")
+	}
+
+	text := NewTextComponent(renderer.Font, value.Text)
+	text.Color = value.Color
+
+	return text, nil
+}
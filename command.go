@@ -0,0 +1,133 @@
+package goga
+
+import (
+	"sort"
+)
+
+// A deferred command, spawning or destroying an actor at next sync point.
+type command struct {
+	actor      *Actor
+	components []interface{}
+	destroy    bool
+	despawn    *ActorPool
+}
+
+// Functions called when an actor gets destroyed.
+type destroyCallbacks struct {
+	actor     *Actor
+	callbacks []func(*Actor)
+}
+
+// Spawns an actor by attaching given components at next sync point.
+// Sync points are after each update step and after rendering, when all systems have run.
+// Use this instead of AttachComponents() while systems are updated.
+func SpawnDeferred(actor *Actor, components ...interface{}) {
+	defaultEngine.SpawnDeferred(actor, components...)
+}
+
+// Spawns an actor by attaching given components at next sync point.
+// See SpawnDeferred() for details.
+func (e *Engine) SpawnDeferred(actor *Actor, components ...interface{}) {
+	e.commands = append(e.commands, command{actor, components, false, nil})
+}
+
+// Destroys an actor at next sync point.
+// Sync points are after each update step and after rendering, when all systems have run.
+// Use this instead of DestroyActor() while systems are updated.
+func DestroyDeferred(actor *Actor) {
+	defaultEngine.DestroyDeferred(actor)
+}
+
+// Destroys an actor at next sync point.
+// See DestroyDeferred() for details.
+func (e *Engine) DestroyDeferred(actor *Actor) {
+	e.commands = append(e.commands, command{actor, nil, true, nil})
+}
+
+// Destroys an actor immediately.
+// The actor is removed from all systems, its components are detached
+// and destroy callbacks are called. Children of the actor are destroyed first.
+// The actor can't be found by name or tag anymore.
+func DestroyActor(actor *Actor) {
+	defaultEngine.DestroyActor(actor)
+}
+
+// Destroys an actor immediately.
+// See DestroyActor() for details.
+func (e *Engine) DestroyActor(actor *Actor) {
+	id := actor.GetId()
+
+	for _, child := range e.GetChildren(id) {
+		e.DestroyActor(child)
+	}
+
+	e.removeFromHierarchy(id)
+	e.removeActorLookup(actor)
+	e.DetachAllComponents(actor)
+	e.RemoveActorById(id)
+	callbacks := e.destroyCallbacks[id]
+	delete(e.destroyCallbacks, id)
+
+	if callbacks != nil {
+		for _, callback := range callbacks.callbacks {
+			callback(actor)
+		}
+	}
+}
+
+// Adds a function called when the actor gets destroyed.
+// This can be used to free resources owned by the actor, like GL buffers of a TextComponent.
+func AddDestroyCallback(actor *Actor, callback func(*Actor)) {
+	defaultEngine.AddDestroyCallback(actor, callback)
+}
+
+// Adds a function called when the actor gets destroyed.
+// See AddDestroyCallback() for details.
+func (e *Engine) AddDestroyCallback(actor *Actor, callback func(*Actor)) {
+	id := actor.GetId()
+	callbacks, ok := e.destroyCallbacks[id]
+
+	if !ok {
+		callbacks = &destroyCallbacks{actor: actor}
+		e.destroyCallbacks[id] = callbacks
+	}
+
+	callbacks.callbacks = append(callbacks.callbacks, callback)
+}
+
+// Destroys all actors having destroy callbacks left, in order of their IDs.
+// This is called on cleanup, so that resources owned by actors are freed.
+func (e *Engine) destroyRemainingActors() {
+	ids := make([]ActorId, 0, len(e.destroyCallbacks))
+
+	for id := range e.destroyCallbacks {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if callbacks, ok := e.destroyCallbacks[id]; ok {
+			e.DestroyActor(callbacks.actor)
+		}
+	}
+}
+
+// Applies deferred commands in the order they were issued.
+// Commands issued by destroy callbacks are applied too.
+func (e *Engine) sync() {
+	for len(e.commands) > 0 {
+		commands := e.commands
+		e.commands = make([]command, 0)
+
+		for _, cmd := range commands {
+			if cmd.destroy {
+				e.DestroyActor(cmd.actor)
+			} else if cmd.despawn != nil {
+				cmd.despawn.Despawn(cmd.actor)
+			} else {
+				e.AttachComponents(cmd.actor, cmd.components...)
+			}
+		}
+	}
+}
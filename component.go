@@ -0,0 +1,479 @@
+package goga
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Identifies the type of a component.
+// This is the reflected type of the component pointer, use ComponentTypeOf() to obtain it.
+type ComponentType reflect.Type
+
+var (
+	// Component types of built-in components
+	Pos2DType             = ComponentTypeOf((*Pos2D)(nil))
+	Pos3DType             = ComponentTypeOf((*Pos3D)(nil))
+	TexType               = ComponentTypeOf((*Tex)(nil))
+	MeshType              = ComponentTypeOf((*Mesh)(nil))
+	KeyframeSetType       = ComponentTypeOf((*KeyframeSet)(nil))
+	KeyframeAnimationType = ComponentTypeOf((*KeyframeAnimation)(nil))
+	TextComponentType     = ComponentTypeOf((*TextComponent)(nil))
+)
+
+// Returns the component type of given component.
+// Components are stored by pointer, so pass a pointer (like &Pos2D{} or (*Pos2D)(nil)).
+func ComponentTypeOf(component interface{}) ComponentType {
+	return reflect.TypeOf(component)
+}
+
+// A component query selects actors by their components.
+// Actors must have all required components and none of the excluded ones.
+// Optional components don't affect matching, but component systems
+// are notified when they change.
+type ComponentQuery struct {
+	Require  []ComponentType
+	Exclude  []ComponentType
+	Optional []ComponentType
+}
+
+// Returns true if an actor having given components matches the query.
+func (q *ComponentQuery) matches(components map[ComponentType]interface{}) bool {
+	for _, ctype := range q.Require {
+		if _, ok := components[ctype]; !ok {
+			return false
+		}
+	}
+
+	for _, ctype := range q.Exclude {
+		if _, ok := components[ctype]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns true if an actor matched the query before given component types changed.
+// Previous holds the components attached before the change, in order of changed types (nil if not attached).
+func (q *ComponentQuery) matchesPrevious(components map[ComponentType]interface{}, changed []ComponentType, previous []interface{}) bool {
+	has := func(ctype ComponentType) bool {
+		for i, t := range changed {
+			if t == ctype {
+				return previous[i] != nil
+			}
+		}
+
+		_, ok := components[ctype]
+		return ok
+	}
+
+	for _, ctype := range q.Require {
+		if !has(ctype) {
+			return false
+		}
+	}
+
+	for _, ctype := range q.Exclude {
+		if has(ctype) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns true if the query refers to given component type.
+func (q *ComponentQuery) refers(ctype ComponentType) bool {
+	for _, types := range [][]ComponentType{q.Require, q.Exclude, q.Optional} {
+		for _, t := range types {
+			if t == ctype {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// A component system gets actors added and removed automatically,
+// when components are attached to or detached from actors within the engine.
+// AddFromStore() is called when an actor matches the query returned by GetQuery(),
+// RemoveById() when it does not match anymore.
+type ComponentSystem interface {
+	System
+	GetQuery() *ComponentQuery
+	AddFromStore(*Actor, *ComponentStore) bool
+}
+
+// The component store keeps components of actors by actor ID and component type.
+type ComponentStore struct {
+	actors     map[ActorId]*Actor
+	components map[ActorId]map[ComponentType]interface{}
+	byType     map[ComponentType]map[ActorId]interface{}
+}
+
+// Creates a new empty component store.
+func NewComponentStore() *ComponentStore {
+	store := &ComponentStore{}
+	store.actors = make(map[ActorId]*Actor)
+	store.components = make(map[ActorId]map[ComponentType]interface{})
+	store.byType = make(map[ComponentType]map[ActorId]interface{})
+
+	return store
+}
+
+// Attaches a component to an actor.
+// A component of the same type attached before will be replaced.
+func (s *ComponentStore) Attach(actor *Actor, component interface{}) {
+	id := actor.GetId()
+	ctype := ComponentTypeOf(component)
+
+	if _, ok := s.components[id]; !ok {
+		s.actors[id] = actor
+		s.components[id] = make(map[ComponentType]interface{})
+	}
+
+	if _, ok := s.byType[ctype]; !ok {
+		s.byType[ctype] = make(map[ActorId]interface{})
+	}
+
+	s.components[id][ctype] = component
+	s.byType[ctype][id] = component
+}
+
+// Detaches the component of given type from an actor.
+// Returns false if the actor has no such component.
+func (s *ComponentStore) Detach(id ActorId, ctype ComponentType) bool {
+	components, ok := s.components[id]
+
+	if !ok {
+		return false
+	}
+
+	if _, ok := components[ctype]; !ok {
+		return false
+	}
+
+	delete(components, ctype)
+	delete(s.byType[ctype], id)
+
+	if len(components) == 0 {
+		delete(s.components, id)
+		delete(s.actors, id)
+	}
+
+	return true
+}
+
+// Detaches all components from an actor and returns the detached component types.
+func (s *ComponentStore) DetachAll(id ActorId) []ComponentType {
+	types := make([]ComponentType, 0, len(s.components[id]))
+
+	for ctype := range s.components[id] {
+		delete(s.byType[ctype], id)
+		types = append(types, ctype)
+	}
+
+	delete(s.components, id)
+	delete(s.actors, id)
+
+	return types
+}
+
+// Returns the actor by ID, or nil if it has no components.
+func (s *ComponentStore) GetActor(id ActorId) *Actor {
+	return s.actors[id]
+}
+
+// Returns the component of given type attached to an actor, or nil if not found.
+func (s *ComponentStore) Get(id ActorId, ctype ComponentType) interface{} {
+	return s.components[id][ctype]
+}
+
+// Returns true if the actor has a component of given type.
+func (s *ComponentStore) Has(id ActorId, ctype ComponentType) bool {
+	_, ok := s.components[id][ctype]
+	return ok
+}
+
+// Returns all components attached to an actor.
+func (s *ComponentStore) GetAll(id ActorId) []interface{} {
+	components := make([]interface{}, 0, len(s.components[id]))
+
+	for _, component := range s.components[id] {
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// Returns the IDs of all actors matching the query, ordered by ID.
+func (s *ComponentStore) Query(query *ComponentQuery) []ActorId {
+	ids := make([]ActorId, 0)
+
+	if len(query.Require) == 0 {
+		for id, components := range s.components {
+			if query.matches(components) {
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		// iterate the smallest set of required components
+		candidates := s.byType[query.Require[0]]
+
+		for _, ctype := range query.Require[1:] {
+			if len(s.byType[ctype]) < len(candidates) {
+				candidates = s.byType[ctype]
+			}
+		}
+
+		for id := range candidates {
+			if query.matches(s.components[id]) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// Returns the IDs of all actors having all given component types, ordered by ID.
+func (s *ComponentStore) QueryTypes(types ...ComponentType) []ActorId {
+	return s.Query(&ComponentQuery{Require: types})
+}
+
+// Returns the Pos2D component of an actor or nil.
+func (s *ComponentStore) GetPos2D(id ActorId) *Pos2D {
+	pos, _ := s.Get(id, Pos2DType).(*Pos2D)
+	return pos
+}
+
+// Returns the Pos3D component of an actor or nil.
+func (s *ComponentStore) GetPos3D(id ActorId) *Pos3D {
+	pos, _ := s.Get(id, Pos3DType).(*Pos3D)
+	return pos
+}
+
+// Returns the Tex component of an actor or nil.
+func (s *ComponentStore) GetTex(id ActorId) *Tex {
+	tex, _ := s.Get(id, TexType).(*Tex)
+	return tex
+}
+
+// Returns the Mesh component of an actor or nil.
+func (s *ComponentStore) GetMesh(id ActorId) *Mesh {
+	mesh, _ := s.Get(id, MeshType).(*Mesh)
+	return mesh
+}
+
+// Returns the KeyframeSet component of an actor or nil.
+func (s *ComponentStore) GetKeyframeSet(id ActorId) *KeyframeSet {
+	set, _ := s.Get(id, KeyframeSetType).(*KeyframeSet)
+	return set
+}
+
+// Returns the KeyframeAnimation component of an actor or nil.
+func (s *ComponentStore) GetKeyframeAnimation(id ActorId) *KeyframeAnimation {
+	animation, _ := s.Get(id, KeyframeAnimationType).(*KeyframeAnimation)
+	return animation
+}
+
+// Returns the TextComponent of an actor or nil.
+func (s *ComponentStore) GetTextComponent(id ActorId) *TextComponent {
+	text, _ := s.Get(id, TextComponentType).(*TextComponent)
+	return text
+}
+
+// Attaches a component to an actor and updates component systems.
+// A component of the same type attached before will be replaced.
+func AttachComponent(actor *Actor, component interface{}) {
+	defaultEngine.AttachComponent(actor, component)
+}
+
+// Attaches a component to an actor and updates component systems.
+// A component of the same type attached before will be replaced.
+func (e *Engine) AttachComponent(actor *Actor, component interface{}) {
+	ctype := ComponentTypeOf(component)
+	previous := e.components.Get(actor.GetId(), ctype)
+	e.components.Attach(actor, component)
+	e.updateMembership(actor.GetId(), []ComponentType{ctype}, []interface{}{previous})
+	e.linkAttachedTransform(actor.GetId(), ctype)
+}
+
+// Attaches components to an actor and updates component systems.
+func AttachComponents(actor *Actor, components ...interface{}) {
+	defaultEngine.AttachComponents(actor, components...)
+}
+
+// Attaches components to an actor and updates component systems.
+func (e *Engine) AttachComponents(actor *Actor, components ...interface{}) {
+	types := make([]ComponentType, 0, len(components))
+	previous := make([]interface{}, 0, len(components))
+
+	for _, component := range components {
+		ctype := ComponentTypeOf(component)
+		types = append(types, ctype)
+		previous = append(previous, e.components.Get(actor.GetId(), ctype))
+		e.components.Attach(actor, component)
+	}
+
+	e.updateMembership(actor.GetId(), types, previous)
+	e.linkAttachedTransform(actor.GetId(), types...)
+}
+
+// Detaches the component of given type from an actor and updates component systems.
+// Returns false if the actor has no such component.
+func DetachComponent(actor *Actor, ctype ComponentType) bool {
+	return defaultEngine.DetachComponent(actor, ctype)
+}
+
+// Detaches the component of given type from an actor and updates component systems.
+// Returns false if the actor has no such component.
+func (e *Engine) DetachComponent(actor *Actor, ctype ComponentType) bool {
+	pos2D := e.components.GetPos2D(actor.GetId())
+	pos3D := e.components.GetPos3D(actor.GetId())
+	previous := e.components.Get(actor.GetId(), ctype)
+
+	if !e.components.Detach(actor.GetId(), ctype) {
+		return false
+	}
+
+	if ctype == Pos2DType {
+		e.unlinkTransform(actor.GetId(), pos2D, nil)
+	} else if ctype == Pos3DType {
+		e.unlinkTransform(actor.GetId(), nil, pos3D)
+	}
+
+	e.updateMembership(actor.GetId(), []ComponentType{ctype}, []interface{}{previous})
+
+	return true
+}
+
+// Detaches all components from an actor and removes it from component systems.
+func DetachAllComponents(actor *Actor) {
+	defaultEngine.DetachAllComponents(actor)
+}
+
+// Detaches all components from an actor and removes it from component systems.
+func (e *Engine) DetachAllComponents(actor *Actor) {
+	pos2D := e.components.GetPos2D(actor.GetId())
+	pos3D := e.components.GetPos3D(actor.GetId())
+	components := e.components.components[actor.GetId()]
+	types := e.components.DetachAll(actor.GetId())
+	previous := make([]interface{}, 0, len(types))
+
+	for _, ctype := range types {
+		previous = append(previous, components[ctype])
+	}
+
+	e.unlinkTransform(actor.GetId(), pos2D, pos3D)
+	e.updateMembership(actor.GetId(), types, previous)
+}
+
+// Returns the component of given type attached to an actor, or nil if not found.
+func GetComponent(id ActorId, ctype ComponentType) interface{} {
+	return defaultEngine.GetComponent(id, ctype)
+}
+
+// Returns the component of given type attached to an actor, or nil if not found.
+func (e *Engine) GetComponent(id ActorId, ctype ComponentType) interface{} {
+	return e.components.Get(id, ctype)
+}
+
+// Returns all actors having all given component types, ordered by ID.
+func QueryActors(types ...ComponentType) []*Actor {
+	return defaultEngine.QueryActors(types...)
+}
+
+// Returns all actors having all given component types, ordered by ID.
+func (e *Engine) QueryActors(types ...ComponentType) []*Actor {
+	ids := e.components.QueryTypes(types...)
+	actors := make([]*Actor, 0, len(ids))
+
+	for _, id := range ids {
+		actors = append(actors, e.components.GetActor(id))
+	}
+
+	return actors
+}
+
+// Returns the component store of the default engine.
+func GetComponentStore() *ComponentStore {
+	return defaultEngine.GetComponentStore()
+}
+
+// Returns the component store of the engine.
+// Modifying it directly won't update component systems.
+func (e *Engine) GetComponentStore() *ComponentStore {
+	return e.components
+}
+
+// Adds or removes the actor to/from component systems referring to one of the changed component types.
+// Previous holds the components attached before the change, in order of changed types (nil if not attached).
+// Actors are added when they start to match the query of a system and removed when they stop to match.
+// Actors still matching are added again if one of the referred components was replaced,
+// so that systems don't keep the replaced component.
+func (e *Engine) updateMembership(id ActorId, changed []ComponentType, previous []interface{}) {
+	actor := e.components.GetActor(id)
+	components := e.components.components[id]
+
+	e.eachSystem(func(system System) {
+		componentSystem, ok := system.(ComponentSystem)
+
+		if !ok {
+			return
+		}
+
+		query := componentSystem.GetQuery()
+		replaced := false
+
+		for i, ctype := range changed {
+			if query.refers(ctype) && previous[i] != components[ctype] {
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			return
+		}
+
+		wasMember := query.matchesPrevious(components, changed, previous)
+		isMember := actor != nil && query.matches(components)
+
+		if wasMember {
+			system.RemoveById(id)
+		}
+
+		if isMember {
+			componentSystem.AddFromStore(actor, e.components)
+		}
+	})
+}
+
+// Links attached position components to the hierarchy.
+func (e *Engine) linkAttachedTransform(id ActorId, types ...ComponentType) {
+	for _, ctype := range types {
+		if ctype == Pos2DType || ctype == Pos3DType {
+			e.linkTransform(id, false)
+			return
+		}
+	}
+}
+
+// Adds all actors matching the query of a component system.
+func (e *Engine) addMatchingActors(system System) {
+	componentSystem, ok := system.(ComponentSystem)
+
+	if !ok {
+		return
+	}
+
+	for _, id := range e.components.Query(componentSystem.GetQuery()) {
+		componentSystem.AddFromStore(e.components.GetActor(id), e.components)
+	}
+}
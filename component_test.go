@@ -0,0 +1,73 @@
+package goga
+
+import (
+	"testing"
+)
+
+type testComponentSystem struct {
+	testSystem
+	added, removed int
+	members        map[ActorId]*Tex
+}
+
+func (s *testComponentSystem) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos2DType, TexType}, Exclude: []ComponentType{KeyframeSetType}}
+}
+
+func (s *testComponentSystem) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	s.added++
+	s.members[actor.GetId()] = store.GetTex(actor.GetId())
+	return true
+}
+
+func (s *testComponentSystem) RemoveById(id ActorId) bool {
+	if _, ok := s.members[id]; !ok {
+		return false
+	}
+
+	s.removed++
+	delete(s.members, id)
+	return true
+}
+
+func TestUpdateMembership(t *testing.T) {
+	e := NewEngine()
+	system := &testComponentSystem{members: make(map[ActorId]*Tex)}
+	e.AddSystem(system)
+	actor := NewActor()
+	tex := &Tex{}
+
+	e.AttachComponent(actor, tex)
+	e.AttachComponent(actor, &Pos2D{})
+
+	if system.added != 1 || system.removed != 0 {
+		t.Fatalf("Expected actor to be added once when becoming member, got %v added, %v removed", system.added, system.removed)
+	}
+
+	e.AttachComponent(actor, &Pos3D{})
+	e.AttachComponent(actor, tex)
+
+	if system.added != 1 || system.removed != 0 {
+		t.Fatal("Expected unrelated and unchanged components not to update membership")
+	}
+
+	replaced := &Tex{}
+	e.AttachComponent(actor, replaced)
+
+	if system.members[actor.GetId()] != replaced {
+		t.Fatal("Expected replaced component to be updated in system")
+	}
+
+	e.AttachComponent(actor, &KeyframeSet{})
+
+	if _, ok := system.members[actor.GetId()]; ok {
+		t.Fatal("Expected actor to be removed when matching an excluded component")
+	}
+
+	e.DetachComponent(actor, KeyframeSetType)
+	e.DetachAllComponents(actor)
+
+	if system.added != 3 || system.removed != 3 || len(system.members) != 0 {
+		t.Fatalf("Expected actor to be added and removed 3 times, got %v added, %v removed", system.added, system.removed)
+	}
+}
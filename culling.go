@@ -1,5 +1,9 @@
 package goga
 
+import (
+	"math"
+)
+
 const (
 	culling_2d_name = "culling2d"
 )
@@ -11,6 +15,7 @@ type Cullable struct {
 
 type Culling2D struct {
 	cullables []Cullable
+	indices   map[ActorId]int
 	viewport  Vec4
 }
 
@@ -19,6 +24,7 @@ type Culling2D struct {
 func NewCulling2D(x, y, width, height int) *Culling2D {
 	culling := &Culling2D{}
 	culling.cullables = make([]Cullable, 0)
+	culling.indices = make(map[ActorId]int)
 	culling.viewport = Vec4{float64(x), float64(y), float64(width), float64(height)}
 
 	return culling
@@ -36,17 +42,26 @@ func (c *Culling2D) Cleanup() {}
 func (c *Culling2D) Add(actor *Actor, pos *Pos2D) bool {
 	id := actor.GetId()
 
-	for _, cull := range c.cullables {
-		if id == cull.Actor.GetId() {
-			return false
-		}
+	if _, ok := c.indices[id]; ok {
+		return false
 	}
 
+	c.indices[id] = len(c.cullables)
 	c.cullables = append(c.cullables, Cullable{actor, pos})
 
 	return true
 }
 
+// Returns the query for actors to cull, which are all textured 2D actors.
+func (c *Culling2D) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos2DType, TexType}}
+}
+
+// Adds actor with Pos2D to the system from component store.
+func (c *Culling2D) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	return c.Add(actor, store.GetPos2D(actor.GetId()))
+}
+
 // Removes actor with Pos2D from system.
 func (c *Culling2D) Remove(actor *Actor) bool {
 	return c.RemoveById(actor.GetId())
@@ -54,19 +69,27 @@ func (c *Culling2D) Remove(actor *Actor) bool {
 
 // Removes actor with Pos2D from system by ID.
 func (c *Culling2D) RemoveById(id ActorId) bool {
-	for i, cull := range c.cullables {
-		if cull.GetId() == id {
-			c.cullables = append(c.cullables[:i], c.cullables[i+1:]...)
-			return true
-		}
+	i, ok := c.indices[id]
+
+	if !ok {
+		return false
 	}
 
-	return false
+	// order doesn't matter for culling, so swap with last
+	last := len(c.cullables) - 1
+	c.cullables[i] = c.cullables[last]
+	c.indices[c.cullables[i].GetId()] = i
+	c.cullables[last] = Cullable{}
+	c.cullables = c.cullables[:last]
+	delete(c.indices, id)
+
+	return true
 }
 
 // Removes all cullable objects.
 func (c *Culling2D) RemoveAll() {
 	c.cullables = make([]Cullable, 0)
+	c.indices = make(map[ActorId]int)
 }
 
 // Returns number of cullable objects.
@@ -74,20 +97,49 @@ func (c *Culling2D) Len() int {
 	return len(c.cullables)
 }
 
+// Returns true if the actor with given ID was added to the system.
+func (c *Culling2D) Has(id ActorId) bool {
+	_, ok := c.indices[id]
+	return ok
+}
+
 func (c *Culling2D) GetName() string {
 	return culling_2d_name
 }
 
 // Updates visibility of all contained sprites.
+// The bounding box is calculated in world space, so rotation, scale and parents are respected.
 func (c *Culling2D) Update(delta float64) {
 	for _, cullable := range c.cullables {
-		if cullable.Pos.X > c.viewport.Z ||
-			cullable.Pos.X+cullable.Size.X < c.viewport.X ||
-			cullable.Pos.Y > c.viewport.W ||
-			cullable.Pos.Y+cullable.Size.Y < c.viewport.Y {
+		if cullable.Actor == nil {
+			continue
+		}
+
+		min, max := calcBounds(cullable.CalcWorld())
+
+		if min.X > c.viewport.Z ||
+			max.X < c.viewport.X ||
+			min.Y > c.viewport.W ||
+			max.Y < c.viewport.Y {
 			cullable.Visible = false
 		} else {
 			cullable.Visible = true
 		}
 	}
 }
+
+// Returns the axis aligned bounding box of the unit rectangle transformed by model matrix.
+func calcBounds(m *Mat3) (Vec2, Vec2) {
+	corners := []Vec2{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	min := Vec2{math.Inf(1), math.Inf(1)}
+	max := Vec2{math.Inf(-1), math.Inf(-1)}
+
+	for _, corner := range corners {
+		x := m.Values[0]*corner.X + m.Values[3]*corner.Y + m.Values[6]
+		y := m.Values[1]*corner.X + m.Values[4]*corner.Y + m.Values[7]
+		min = Vec2{math.Min(min.X, x), math.Min(min.Y, y)}
+		max = Vec2{math.Max(max.X, x), math.Max(max.Y, y)}
+	}
+
+	return min, max
+}
@@ -80,4 +80,32 @@ const (
 		void main(){
 			c = texture(tex, tc)*color;
 		}`
+
+	// constants for transition shader
+	Default_shader_transition_vertex_attrib = "vertex"
+	Default_shader_transition_offset        = "offset"
+	Default_shader_transition_tex           = "tex"
+	Default_shader_transition_alpha         = "alpha"
+	Default_shader_transition_color         = "color"
+	Default_shader_transition_amount        = "amount"
+
+	// source for transition shader
+	default_shader_transition_vertex_src = `#version 130
+		uniform vec2 offset;
+		in vec2 vertex;
+		out vec2 tc;
+		void main(){
+			tc = vertex;
+			gl_Position = vec4((vertex+offset)*2.0-1.0, 0.0, 1.0);
+		}`
+	default_shader_transition_fragment_src = `#version 130
+		precision highp float;
+		uniform sampler2D tex;
+		uniform float alpha, amount;
+		uniform vec4 color;
+		in vec2 tc;
+		out vec4 c;
+		void main(){
+			c = vec4(mix(texture(tex, tc).rgb, color.rgb, amount), alpha);
+		}`
 )
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"embed"
 	"github.com/DeKugelschieber/go-game"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 const (
-	gopher_path = "src/github.com/DeKugelschieber/go-game/demo/input/assets/gopher.png"
+	gopher_path = "assets/gopher.png"
 )
 
+//go:embed assets
+var assets embed.FS
+
 type Game struct {
 	mouseX, mouseY float64
 	sprite         *goga.Sprite
 }
 
 func (g *Game) Setup() {
+	// mount assets embedded into the binary
+	goga.MountFS(assets)
+
 	// load texture
 	_, err := goga.LoadRes(gopher_path)
 
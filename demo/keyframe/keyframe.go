@@ -1,16 +1,23 @@
 package main
 
 import (
+	"embed"
 	"github.com/DeKugelschieber/go-game"
 )
 
 const (
-	assets_dir = "src/github.com/DeKugelschieber/go-game/demo/keyframe/assets"
+	assets_dir = "assets"
 )
 
+//go:embed assets
+var assets embed.FS
+
 type Game struct{}
 
 func (g *Game) Setup() {
+	// mount assets embedded into the binary
+	goga.MountFS(assets)
+
 	err := goga.LoadResFromFolder(assets_dir)
 
 	if err != nil {
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"embed"
 	"github.com/DeKugelschieber/go-game"
 )
 
 const (
-	assets_dir = "src/github.com/DeKugelschieber/go-game/demo/model/assets"
+	assets_dir = "assets"
 )
 
+//go:embed assets
+var assets embed.FS
+
 type Game struct {
 	model *goga.Model
 }
 
 func (g *Game) Setup() {
+	// mount assets embedded into the binary
+	goga.MountFS(assets)
+
 	// load texture and ply mesh
 	err := goga.LoadResFromFolder(assets_dir)
 
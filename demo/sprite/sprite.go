@@ -1,16 +1,23 @@
 package main
 
 import (
+	"embed"
 	"github.com/DeKugelschieber/go-game"
 )
 
 const (
-	gopher_path = "src/github.com/DeKugelschieber/go-game/demo/sprite/assets/gopher.png"
+	gopher_path = "assets/gopher.png"
 )
 
+//go:embed assets
+var assets embed.FS
+
 type Game struct{}
 
 func (g *Game) Setup() {
+	// mount assets embedded into the binary
+	goga.MountFS(assets)
+
 	// load texture
 	_, err := goga.LoadRes(gopher_path)
 
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"embed"
 	"github.com/DeKugelschieber/go-game"
 	"github.com/go-gl/gl/v3.2-core/gl"
 )
 
 const (
-	font_path = "src/github.com/DeKugelschieber/go-game/demo/text/assets/victor.png"
-	font_json = "src/github.com/DeKugelschieber/go-game/demo/text/assets/victor.json"
+	font_path = "assets/victor.png"
+	font_json = "assets/victor.json"
 )
 
+//go:embed assets
+var assets embed.FS
+
 type Game struct{}
 
 func (g *Game) Setup() {
+	// mount assets embedded into the binary
+	goga.MountFS(assets)
+
 	// load texture
 	pngLoader, ok := goga.GetLoaderByExt("png").(*goga.PngLoader)
 
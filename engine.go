@@ -0,0 +1,118 @@
+package goga
+
+import (
+	"encoding/json"
+	"time"
+)
+
+var (
+	defaultEngine = NewEngine()
+)
+
+// The engine owns the state of a game, which are systems, scenes, components, resources, loaders,
+// input listeners and default resources (camera and shaders).
+// The package functions (like AddSystem()) are wrappers around the default engine.
+// Additional engines can be created to run isolated games side by side, for example in tests or tools.
+type Engine struct {
+	running        bool
+	headless       bool
+	clearColor     Vec4
+	clearBuffer    []uint32
+	viewportWidth  int
+	viewportHeight int
+	alpha          float64
+
+	systems          []systemEntry
+	activeBuffer     []systemEntry
+	scenes           []Scene
+	sceneStack       []Scene
+	sceneData        map[Scene]*sceneData
+	transition       *transitionState
+	components       *ComponentStore
+	commands         []command
+	destroyCallbacks map[ActorId]*destroyCallbacks
+	hierarchy        map[ActorId]*actorNode
+	actorNames       map[string]*Actor
+	actorTags        map[string]map[ActorId]*Actor
+	codecs           []ComponentCodec
+	prefabs          map[string]*Prefab
+	mounts           []mount
+	resloader        []ResLoader
+	resources        []Res
+	resRefs          map[Res]int
+	resOptions       map[Res]json.RawMessage
+	resGroups        map[string]*resGroup
+	asyncHandles     []*ResLoadHandle
+	asyncWorkers     chan bool
+	asyncUploadLimit int
+	reloadInterval   time.Duration
+	lastReloadPoll   time.Time
+	resStates        map[Res]fileState
+	reloadCallbacks  map[string][]func(Res)
+	watchedFiles     map[string]*watchedFile
+	keyboardListener []KeyboardListener
+	mouseListener    []MouseListener
+
+	// Default resources
+	DefaultCamera     *Camera
+	Default2DShader   *Shader
+	Default3DShader   *Shader
+	DefaultTextShader *Shader
+
+	transitionRenderer *TransitionRenderer
+}
+
+// Creates a new empty engine.
+func NewEngine() *Engine {
+	engine := &Engine{}
+	engine.running = true
+	engine.alpha = 1
+	engine.clearBuffer = make([]uint32, 0)
+	engine.systems = make([]systemEntry, 0)
+	engine.activeBuffer = make([]systemEntry, 0)
+	engine.scenes = make([]Scene, 0)
+	engine.sceneStack = make([]Scene, 0)
+	engine.sceneData = make(map[Scene]*sceneData)
+	engine.components = NewComponentStore()
+	engine.commands = make([]command, 0)
+	engine.destroyCallbacks = make(map[ActorId]*destroyCallbacks)
+	engine.hierarchy = make(map[ActorId]*actorNode)
+	engine.actorNames = make(map[string]*Actor)
+	engine.actorTags = make(map[string]map[ActorId]*Actor)
+	engine.codecs = make([]ComponentCodec, 0)
+	engine.registerDefaultCodecs()
+	engine.prefabs = make(map[string]*Prefab)
+	engine.mounts = make([]mount, 0)
+	engine.resloader = make([]ResLoader, 0)
+	engine.resources = make([]Res, 0)
+	engine.resRefs = make(map[Res]int)
+	engine.resOptions = make(map[Res]json.RawMessage)
+	engine.resGroups = make(map[string]*resGroup)
+	engine.asyncHandles = make([]*ResLoadHandle, 0)
+	engine.asyncWorkers = make(chan bool, async_res_workers)
+	engine.asyncUploadLimit = default_async_upload_limit
+	engine.resStates = make(map[Res]fileState)
+	engine.reloadCallbacks = make(map[string][]func(Res))
+	engine.watchedFiles = make(map[string]*watchedFile)
+	engine.keyboardListener = make([]KeyboardListener, 0)
+	engine.mouseListener = make([]MouseListener, 0)
+
+	return engine
+}
+
+// Returns the default engine used by package functions.
+func GetDefaultEngine() *Engine {
+	return defaultEngine
+}
+
+// Makes the default resources of the default engine available as package variables.
+func (e *Engine) exportDefaults() {
+	if e != defaultEngine {
+		return
+	}
+
+	DefaultCamera = e.DefaultCamera
+	Default2DShader = e.Default2DShader
+	Default3DShader = e.Default3DShader
+	DefaultTextShader = e.DefaultTextShader
+}
@@ -44,6 +44,23 @@ func NewFBOWithTex2D(width, height, filter int32) (*FBO, *Tex) {
 	return fbo, tex
 }
 
+// Creates a new FBO with given target, 2D texture and depth render buffer.
+// Used to render 3D scenes to texture.
+func NewFBOWithTex2DAndDepth(width, height, filter int32) (*FBO, *Tex, *RBO) {
+	fbo, tex := NewFBOWithTex2D(width, height, filter)
+
+	depth := NewRBO()
+	depth.Bind()
+	depth.Storage(gl.DEPTH_COMPONENT24, width, height)
+	depth.Unbind()
+
+	fbo.Bind()
+	fbo.Renderbuffer(gl.DEPTH_ATTACHMENT, depth.GetId())
+	fbo.Unbind()
+
+	return fbo, tex, depth
+}
+
 // Drops the FBO.
 func (f *FBO) Drop() {
 	gl.DeleteFramebuffers(1, &f.id)
@@ -102,6 +119,12 @@ func (f *FBO) Texture3D(attachment, texId uint32, level, layer int32) {
 	gl.FramebufferTexture3D(f.target, attachment, gl.TEXTURE_3D, texId, level, layer)
 }
 
+// Attaches a render buffer.
+// Render buffers are not used as draw buffers, so this is meant for depth and stencil attachments.
+func (f *FBO) Renderbuffer(attachment, rboId uint32) {
+	gl.FramebufferRenderbuffer(f.target, attachment, gl.RENDERBUFFER, rboId)
+}
+
 // Returns the status of the FBO.
 func (f *FBO) GetStatus() uint32 {
 	return gl.CheckFramebufferStatus(f.target)
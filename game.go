@@ -14,16 +14,11 @@ const (
 	default_height        = uint32(400)
 	default_title         = "Game"
 	default_exit_on_close = true
+	default_max_steps     = 5
 )
 
 var (
-	running        = true
-	clearColor     = Vec4{}
-	clearBuffer    []uint32
-	viewportWidth  int
-	viewportHeight int
-
-	// Default resources
+	// Default resources of the default engine
 	DefaultCamera     *Camera
 	Default2DShader   *Shader
 	Default3DShader   *Shader
@@ -34,6 +29,11 @@ var (
 type ResizeCallback func(width, height int)
 
 // Run options allow to set some parameters on startup.
+// If FixedStep is set (in seconds, e.g. 1/60), simulation systems and the game
+// are updated in fixed steps, while render systems are updated once per frame.
+// MaxSteps limits the number of simulation steps per frame (default 5),
+// time that could not be caught up with is dropped.
+// The built-in renderers draw the current state and do not interpolate, see GetAlpha().
 type RunOptions struct {
 	Title               string
 	Width               uint32
@@ -46,11 +46,15 @@ type RunOptions struct {
 	RefreshRate         int
 	Fullscreen          bool
 	MonitorId           uint // index
+	FixedStep           float64
+	MaxSteps            int
 }
 
 // Main game object.
 // Setup will be called before the main loop and after GL context has been created.
-// Update will be called each frame. This can be used to switch scenes or end game on win state.
+// Update will be called each frame after simulation systems and before rendering.
+// This can be used to switch scenes or end game on win state.
+// When running with a fixed time step, Update will be called on each simulation step instead.
 // For game logic, System should be used.
 type Game interface {
 	Setup()
@@ -69,6 +73,12 @@ func init() {
 // If options is nil, the default options will be used.
 // This function will panic on error.
 func Run(game Game, options *RunOptions) {
+	defaultEngine.Run(game, options)
+}
+
+// Creates a new window with given options and starts the game using this engine.
+// See Run() for details.
+func (e *Engine) Run(game Game, options *RunOptions) {
 	// init GL
 	log.Print("Initializing GL")
 
@@ -141,37 +151,46 @@ func Run(game Game, options *RunOptions) {
 	// window event handlers
 	wnd.SetSizeCallback(func(w *glfw.Window, width, height int) {
 		if options == nil {
-			SetViewport(0, 0, int32(width), int32(height))
+			e.SetViewport(0, 0, int32(width), int32(height))
 		} else if options != nil && options.SetViewportOnResize {
-			SetViewport(0, 0, int32(width), int32(height))
+			e.SetViewport(0, 0, int32(width), int32(height))
 		}
 
-		if activeScene != nil {
-			activeScene.Resize(width, height)
-		}
+		e.resizeScenes(width, height)
 
 		if options != nil && options.ResizeCallbackFunc != nil {
 			options.ResizeCallbackFunc(width, height)
 		}
 	})
 
-	initInput(wnd)
+	e.initInput(wnd)
 
 	// make GL context current
 	wnd.MakeContextCurrent()
 
 	// init go-game
 	log.Print("Initializing goga")
-	initGoga(int(width), int(height))
+	e.initGoga(int(width), int(height))
 
 	if options != nil && options.Width > 0 && options.Height > 0 {
-		SetViewport(0, 0, int32(options.Width), int32(options.Height))
+		e.SetViewport(0, 0, int32(options.Width), int32(options.Height))
 	} else {
-		SetViewport(0, 0, int32(default_width), int32(default_height))
+		e.SetViewport(0, 0, int32(default_width), int32(default_height))
 	}
 
 	if options != nil {
-		clearColor = options.ClearColor
+		e.clearColor = options.ClearColor
+	}
+
+	fixedStep := float64(0)
+	maxSteps := default_max_steps
+
+	if options != nil {
+		fixedStep = options.FixedStep
+
+		if options.MaxSteps > 0 {
+			maxSteps = options.MaxSteps
+		}
 	}
 
 	game.Setup()
@@ -179,24 +198,27 @@ func Run(game Game, options *RunOptions) {
 	// start and loop
 	log.Print("Starting main loop")
 	delta := time.Duration(0)
-	var deltaSec float64
+	var deltaSec, accumulator float64
 
 	for {
-		if !running || exitOnClose && wnd.ShouldClose() {
-			cleanup()
+		if !e.running || exitOnClose && wnd.ShouldClose() {
+			e.cleanup()
 			return
 		}
 
 		start := time.Now()
-		gl.ClearColor(float32(clearColor.X), float32(clearColor.Y), float32(clearColor.Z), float32(clearColor.W))
-
-		for _, buffer := range clearBuffer {
-			gl.Clear(buffer)
-		}
+		e.clear()
+		e.uploadAsyncRes()
+		e.pollHotReload()
 
 		if !math.IsInf(deltaSec, 0) && !math.IsInf(deltaSec, -1) {
-			updateSystems(deltaSec)
-			game.Update(deltaSec)
+			if fixedStep > 0 {
+				accumulator = e.updateFixed(game, deltaSec, fixedStep, accumulator, maxSteps)
+			} else {
+				e.update(game, deltaSec)
+			}
+
+			e.render(deltaSec)
 		}
 
 		CheckGLError()
@@ -208,11 +230,98 @@ func Run(game Game, options *RunOptions) {
 	}
 }
 
-func initGoga(width, height int) {
+// Runs the game without window and GL context.
+// Setup is called once, afterwards Update and all systems are updated for given number of frames
+// using the passed delta (in seconds). The loop ends early when Stop() is called.
+// As there is no GL context, GL backed default systems, loaders and default shaders won't be available.
+// This can be used to test game logic, scenes and custom systems.
+func RunHeadless(game Game, frames int, delta float64) {
+	defaultEngine.RunHeadless(game, frames, delta)
+}
+
+// Runs the game without window and GL context using this engine.
+// See RunHeadless() for details.
+func (e *Engine) RunHeadless(game Game, frames int, delta float64) {
+	log.Print("Initializing goga headless")
+	e.running = true
+	e.headless = true
+	e.initHeadless(int(default_width), int(default_height))
+	game.Setup()
+
+	log.Print("Starting headless loop")
+
+	for i := 0; i < frames && e.running; i++ {
+		e.uploadAsyncRes()
+		e.pollHotReload()
+		e.update(game, delta)
+		e.render(delta)
+	}
+
+	e.cleanup()
+	e.headless = false
+}
+
+// Updates simulation systems, scenes and game.
+func (e *Engine) update(game Game, delta float64) {
+	e.updateSimulationSystems(delta)
+	e.updateScenes(delta)
+	game.Update(delta)
+	e.sync()
+}
+
+// Updates simulation systems, scenes and game in fixed steps using the accumulated frame time.
+// Returns the time left in accumulator.
+func (e *Engine) updateFixed(game Game, delta, step, accumulator float64, maxSteps int) float64 {
+	accumulator += delta
+	steps := 0
+
+	for accumulator >= step && steps < maxSteps {
+		e.update(game, step)
+		accumulator -= step
+		steps++
+	}
+
+	// drop time we cannot catch up with, to prevent spiral of death
+	if accumulator >= step {
+		accumulator = math.Mod(accumulator, step)
+	}
+
+	e.alpha = accumulator / step
+
+	return accumulator
+}
+
+// Updates render systems and draws scenes, or the scene transition if running.
+func (e *Engine) render(delta float64) {
+	if e.transition != nil {
+		e.renderTransition(delta)
+	} else {
+		e.renderScenes(e.sceneStack, delta)
+	}
+
+	e.sync()
+}
+
+// Updates render systems and draws scenes for given scene stack.
+func (e *Engine) renderScenes(stack []Scene, delta float64) {
+	e.updateRenderSystems(stack, delta)
+	drawScenes(stack, delta)
+}
+
+// Clears the buffers set by ClearColorBuffer() and ClearDepthBuffer().
+func (e *Engine) clear() {
+	gl.ClearColor(float32(e.clearColor.X), float32(e.clearColor.Y), float32(e.clearColor.Z), float32(e.clearColor.W))
+
+	for _, buffer := range e.clearBuffer {
+		gl.Clear(buffer)
+	}
+}
+
+func (e *Engine) initGoga(width, height int) {
 	// default camera
-	DefaultCamera = NewCamera(0, 0, width, height)
-	DefaultCamera.CalcRatio()
-	DefaultCamera.CalcOrtho()
+	e.DefaultCamera = NewCamera(0, 0, width, height)
+	e.DefaultCamera.CalcRatio()
+	e.DefaultCamera.CalcOrtho()
 
 	// default 2D shader
 	shader, err := NewShader(default_shader_2d_vertex_src, default_shader_2d_fragment_src)
@@ -221,9 +330,9 @@ func initGoga(width, height int) {
 		panic(err)
 	}
 
-	Default2DShader = shader
-	Default2DShader.BindAttrib(Default_shader_2D_vertex_attrib)
-	Default2DShader.BindAttrib(Default_shader_2D_texcoord_attrib)
+	e.Default2DShader = shader
+	e.Default2DShader.BindAttrib(Default_shader_2D_vertex_attrib)
+	e.Default2DShader.BindAttrib(Default_shader_2D_texcoord_attrib)
 
 	// default 3D shader
 	shader, err = NewShader(default_shader_3d_vertex_src, default_shader_3d_fragment_src)
@@ -232,9 +341,9 @@ func initGoga(width, height int) {
 		panic(err)
 	}
 
-	Default3DShader = shader
-	Default3DShader.BindAttrib(Default_shader_3D_vertex_attrib)
-	Default3DShader.BindAttrib(Default_shader_3D_texcoord_attrib)
+	e.Default3DShader = shader
+	e.Default3DShader.BindAttrib(Default_shader_3D_vertex_attrib)
+	e.Default3DShader.BindAttrib(Default_shader_3D_texcoord_attrib)
 
 	// default text shader
 	shader, err = NewShader(default_shader_text_vertex_src, default_shader_text_fragment_src)
@@ -243,28 +352,75 @@ func initGoga(width, height int) {
 		panic(err)
 	}
 
-	DefaultTextShader = shader
-	DefaultTextShader.BindAttrib(Default_shader_text_vertex_attrib)
-	DefaultTextShader.BindAttrib(Default_shader_text_texcoord_attrib)
+	e.DefaultTextShader = shader
+	e.DefaultTextShader.BindAttrib(Default_shader_text_vertex_attrib)
+	e.DefaultTextShader.BindAttrib(Default_shader_text_texcoord_attrib)
+	e.exportDefaults()
 
 	// settings and registration
-	ClearColorBuffer(true)
-	EnableAlphaBlending(true)
-	AddLoader(&PngLoader{gl.LINEAR, false})
-	AddLoader(&PlyLoader{gl.STATIC_DRAW})
-	AddSystem(NewSpriteRenderer(nil, nil, false))
-	AddSystem(NewModelRenderer(nil, nil, false))
-	AddSystem(NewCulling2D(0, 0, width, height))
-	AddSystem(NewKeyframeRenderer(nil, nil))
-	AddSystem(NewTextRenderer(nil, nil, nil)) // font must be set outside!
-}
-
-func cleanup() {
+	e.ClearColorBuffer(true)
+	e.EnableAlphaBlending(true)
+	e.AddLoader(&PngLoader{gl.LINEAR, false})
+	e.AddLoader(NewJpegLoader(gl.LINEAR, false))
+	e.AddLoader(NewGifLoader(gl.LINEAR, false, false))
+	e.AddLoader(NewBmpLoader(gl.LINEAR, false))
+	e.AddLoader(NewTgaLoader(gl.LINEAR, false))
+	e.AddLoader(NewWebpLoader(gl.LINEAR, false))
+	e.AddLoader(&PlyLoader{gl.STATIC_DRAW})
+	e.AddLoader(&ObjLoader{gl.STATIC_DRAW})
+	e.AddLoader(&MtlLoader{})
+	e.AddLoader(&GltfLoader{gl.STATIC_DRAW})
+	e.AddSystemWithPriority(NewCulling2D(0, 0, width, height), Culling_system_priority)
+	e.AddSystemWithPriority(NewSpriteRenderer(e.Default2DShader, e.DefaultCamera, false), Render_system_priority)
+	e.AddSystemWithPriority(NewModelRenderer(e.Default3DShader, e.DefaultCamera, false), Render_system_priority)
+	e.AddSystemWithPriority(NewKeyframeRenderer(e.Default2DShader, e.DefaultCamera), Render_system_priority)
+	e.AddSystemWithPriority(NewTextRenderer(e.DefaultTextShader, e.DefaultCamera, nil), Render_system_priority) // font must be set outside!
+}
+
+func (e *Engine) initHeadless(width, height int) {
+	// default camera
+	e.DefaultCamera = NewCamera(0, 0, width, height)
+	e.DefaultCamera.CalcRatio()
+	e.DefaultCamera.CalcOrtho()
+	e.viewportWidth = width
+	e.viewportHeight = height
+	e.exportDefaults()
+
+	// systems not depending on GL
+	e.AddSystemWithPriority(NewCulling2D(0, 0, width, height), Culling_system_priority)
+}
+
+func (e *Engine) cleanup() {
+	// cleanup scenes first, as they release their resources
+	log.Printf("Cleaning up %v scenes", len(e.scenes))
+	e.RemoveAllScenes()
+	e.destroyRemainingActors()
+	e.components = NewComponentStore()
+	e.commands = make([]command, 0)
+	e.destroyCallbacks = make(map[ActorId]*destroyCallbacks)
+	e.hierarchy = make(map[ActorId]*actorNode)
+	e.actorNames = make(map[string]*Actor)
+	e.actorTags = make(map[string]map[ActorId]*Actor)
+
+	// cleanup systems
+	log.Printf("Cleaning up %v systems", len(e.systems))
+	e.RemoveAllSystems()
+
 	// cleanup resources
-	log.Printf("Trying to cleaning up %v resources", len(resources))
+	e.asyncHandles = make([]*ResLoadHandle, 0)
+	e.DisableHotReload()
+	e.reloadCallbacks = make(map[string][]func(Res))
+	e.watchedFiles = make(map[string]*watchedFile)
+
+	for name := range e.resGroups {
+		e.UnloadResGroup(name)
+	}
+
+	e.reportLeakedRes()
+	log.Printf("Trying to cleaning up %v resources", len(e.resources))
 	dropped := 0
 
-	for _, res := range resources {
+	for _, res := range e.resources {
 		if drop, ok := res.(Dropable); ok {
 			drop.Drop()
 			dropped++
@@ -272,58 +428,79 @@ func cleanup() {
 	}
 
 	log.Printf("Dropped %v resources", dropped)
+	e.RemoveAllRes()
+	e.resGroups = make(map[string]*resGroup)
+	e.UnmountAll()
 
-	// cleanup systems
-	log.Printf("Cleaning up %v systems", len(systems))
+	// cleanup default
+	log.Print("Cleaning up default resources")
+	e.endTransition()
 
-	for _, system := range systems {
-		system.Cleanup()
+	if e.transitionRenderer != nil {
+		e.transitionRenderer.Drop()
+		e.transitionRenderer = nil
 	}
 
-	// cleanup scenes
-	log.Printf("Cleaning up %v scenes", len(scenes))
-
-	for _, scene := range scenes {
-		scene.Cleanup()
+	if e.Default2DShader != nil {
+		e.Default2DShader.Drop()
 	}
 
-	// cleanup default
-	log.Print("Cleaning up default resources")
+	if e.Default3DShader != nil {
+		e.Default3DShader.Drop()
+	}
 
-	Default2DShader.Drop()
-	DefaultTextShader.Drop()
+	if e.DefaultTextShader != nil {
+		e.DefaultTextShader.Drop()
+	}
 }
 
 // Stops the game and closes the window.
 func Stop() {
+	defaultEngine.Stop()
+}
+
+// Stops the game and closes the window.
+func (e *Engine) Stop() {
 	log.Print("Stopping main loop")
-	running = false
+	e.running = false
 }
 
 // Adds color buffer to list of buffers to be cleared.
 // If parameter is false, it will be removed.
 func ClearColorBuffer(do bool) {
-	removeClearBuffer(gl.COLOR_BUFFER_BIT)
+	defaultEngine.ClearColorBuffer(do)
+}
+
+// Adds color buffer to list of buffers to be cleared.
+// If parameter is false, it will be removed.
+func (e *Engine) ClearColorBuffer(do bool) {
+	e.removeClearBuffer(gl.COLOR_BUFFER_BIT)
 
 	if do {
-		clearBuffer = append(clearBuffer, gl.COLOR_BUFFER_BIT)
+		e.clearBuffer = append(e.clearBuffer, gl.COLOR_BUFFER_BIT)
 	}
 }
 
 // Adds depth buffer to list of buffers to be cleared.
 // If parameter is false, it will be removed.
 func ClearDepthBuffer(do bool) {
-	removeClearBuffer(gl.DEPTH_BUFFER_BIT)
+	defaultEngine.ClearDepthBuffer(do)
+}
+
+// Adds depth buffer to list of buffers to be cleared.
+// If parameter is false, it will be removed.
+func (e *Engine) ClearDepthBuffer(do bool) {
+	e.removeClearBuffer(gl.DEPTH_BUFFER_BIT)
 
 	if do {
-		clearBuffer = append(clearBuffer, gl.DEPTH_BUFFER_BIT)
+		e.clearBuffer = append(e.clearBuffer, gl.DEPTH_BUFFER_BIT)
 	}
 }
 
-func removeClearBuffer(buffer uint32) {
-	for i, b := range clearBuffer {
+func (e *Engine) removeClearBuffer(buffer uint32) {
+	for i, b := range e.clearBuffer {
 		if b == buffer {
-			clearBuffer = append(clearBuffer[:i], clearBuffer[i+1:]...)
+			e.clearBuffer = append(e.clearBuffer[:i], e.clearBuffer[i+1:]...)
 			return
 		}
 	}
@@ -332,6 +509,16 @@ func removeClearBuffer(buffer uint32) {
 // Enables/Disables alpha blending by source alpha channel.
 // BLEND = SRC_ALPHA | ONE_MINUS_SRC_ALPHA
 func EnableAlphaBlending(enable bool) {
+	defaultEngine.EnableAlphaBlending(enable)
+}
+
+// Enables/Disables alpha blending by source alpha channel.
+// BLEND = SRC_ALPHA | ONE_MINUS_SRC_ALPHA
+func (e *Engine) EnableAlphaBlending(enable bool) {
+	if e.headless {
+		return
+	}
+
 	if enable {
 		gl.Enable(gl.BLEND)
 		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
@@ -342,6 +529,15 @@ func EnableAlphaBlending(enable bool) {
 
 // Enables/Disables depth test.
 func EnableDepthTest(enable bool) {
+	defaultEngine.EnableDepthTest(enable)
+}
+
+// Enables/Disables depth test.
+func (e *Engine) EnableDepthTest(enable bool) {
+	if e.headless {
+		return
+	}
+
 	if enable {
 		gl.Enable(gl.DEPTH_TEST)
 	} else {
@@ -351,35 +547,72 @@ func EnableDepthTest(enable bool) {
 
 // Sets GL viewport and updates default resources and systems.
 func SetViewport(x, y, width, height int32) {
-	viewportWidth = int(width)
-	viewportHeight = int(height)
+	defaultEngine.SetViewport(x, y, width, height)
+}
 
-	DefaultCamera.SetViewport(int(x), int(y), viewportWidth, viewportHeight)
-	DefaultCamera.CalcRatio()
-	DefaultCamera.CalcOrtho()
+// Sets GL viewport and updates default resources and systems.
+func (e *Engine) SetViewport(x, y, width, height int32) {
+	e.viewportWidth = int(width)
+	e.viewportHeight = int(height)
 
-	if culling2d := GetSystemByName(culling_2d_name); culling2d != nil {
-		system, ok := culling2d.(*Culling2D)
+	e.DefaultCamera.SetViewport(int(x), int(y), e.viewportWidth, e.viewportHeight)
+	e.DefaultCamera.CalcRatio()
+	e.DefaultCamera.CalcOrtho()
 
-		if ok {
-			system.SetViewport(int(x), int(y), viewportWidth, viewportHeight)
+	e.eachSystem(func(system System) {
+		if culling2d, ok := system.(*Culling2D); ok {
+			culling2d.SetViewport(int(x), int(y), e.viewportWidth, e.viewportHeight)
 		}
+	})
+
+	if !e.headless {
+		gl.Viewport(x, y, width, height)
 	}
 
-	gl.Viewport(x, y, width, height)
+	e.resizeTransition()
 }
 
 // Sets GL clear color.
 func SetClearColor(r, g, b, a float64) {
-	clearColor = Vec4{r, g, b, a}
+	defaultEngine.SetClearColor(r, g, b, a)
+}
+
+// Sets GL clear color.
+func (e *Engine) SetClearColor(r, g, b, a float64) {
+	e.clearColor = Vec4{r, g, b, a}
 }
 
 // Returns width of viewport.
 func GetWidth() int {
-	return viewportWidth
+	return defaultEngine.GetWidth()
+}
+
+// Returns width of viewport.
+func (e *Engine) GetWidth() int {
+	return e.viewportWidth
 }
 
 // Returns height of viewport.
 func GetHeight() int {
-	return viewportHeight
+	return defaultEngine.GetHeight()
+}
+
+// Returns height of viewport.
+func (e *Engine) GetHeight() int {
+	return e.viewportHeight
+}
+
+// Returns the interpolation factor between the previous and the current simulation step [0, 1).
+// Without fixed time step, it is always 1.
+// The built-in renderers do not interpolate, they draw positions as set by the last simulation step.
+// To smooth movement, the game must keep the previous position of an actor, update it before each step
+// and set the rendered position to LerpVec2(previous, current, GetAlpha()) in a render system.
+func GetAlpha() float64 {
+	return defaultEngine.GetAlpha()
+}
+
+// Returns the interpolation factor between the previous and the current simulation step [0, 1).
+// See GetAlpha() for details.
+func (e *Engine) GetAlpha() float64 {
+	return e.alpha
 }
@@ -0,0 +1,132 @@
+package goga
+
+import (
+	"testing"
+)
+
+type testScene struct {
+	name    string
+	updates int
+	paused  int
+	resumed int
+	cleaned bool
+}
+
+func (s *testScene) Pause()          { s.paused++ }
+func (s *testScene) Resume()         { s.resumed++ }
+func (s *testScene) Cleanup()        { s.cleaned = true }
+func (s *testScene) Resize(int, int) {}
+func (s *testScene) GetName() string { return s.name }
+func (s *testScene) Update(float64)  { s.updates++ }
+
+type testSystem struct {
+	updates int
+	delta   float64
+	removed bool
+}
+
+func (s *testSystem) Update(delta float64) {
+	s.updates++
+	s.delta += delta
+}
+
+func (s *testSystem) Cleanup()                { s.removed = true }
+func (s *testSystem) Remove(*Actor) bool      { return false }
+func (s *testSystem) RemoveById(ActorId) bool { return false }
+func (s *testSystem) RemoveAll()              {}
+func (s *testSystem) Len() int                { return 0 }
+func (s *testSystem) GetName() string         { return "testSystem" }
+
+type testGame struct {
+	engine      *Engine
+	menu, level *testScene
+	system      *testSystem
+	frames      int
+	stopAt      int
+	activeAt    []string
+}
+
+func (g *testGame) Setup() {
+	g.engine.AddScene(g.menu)
+	g.engine.AddScene(g.level)
+	g.engine.AddSystem(g.system)
+}
+
+func (g *testGame) Update(delta float64) {
+	g.frames++
+	g.activeAt = append(g.activeAt, g.engine.GetActiveScene().GetName())
+
+	if g.frames == 2 {
+		g.engine.SwitchScene(g.level)
+	}
+
+	if g.frames == g.stopAt {
+		g.engine.Stop()
+	}
+}
+
+func newTestGame(stopAt int) *testGame {
+	return &testGame{engine: NewEngine(),
+		menu:   &testScene{name: "menu"},
+		level:  &testScene{name: "level"},
+		system: &testSystem{},
+		stopAt: stopAt}
+}
+
+func TestRunHeadless(t *testing.T) {
+	game := newTestGame(0)
+	game.engine.RunHeadless(game, 4, 0.5)
+
+	if game.frames != 4 {
+		t.Fatalf("Expected 4 frames, got %v", game.frames)
+	}
+
+	if game.system.updates != 4 || game.system.delta != 2 {
+		t.Fatalf("Expected system to be updated 4 times for 2 seconds, got %v times for %v", game.system.updates, game.system.delta)
+	}
+
+	if !game.system.removed {
+		t.Fatal("Expected system to be cleaned up")
+	}
+
+	expected := []string{"menu", "menu", "level", "level"}
+
+	for i, name := range expected {
+		if game.activeAt[i] != name {
+			t.Fatalf("Expected scene %v in frame %v, got %v", name, i, game.activeAt[i])
+		}
+	}
+
+	if game.menu.updates != 2 || game.level.updates != 2 {
+		t.Fatalf("Expected each scene to be updated twice, got %v and %v", game.menu.updates, game.level.updates)
+	}
+
+	if game.menu.paused != 1 || game.level.resumed != 1 {
+		t.Fatal("Expected menu to be paused and level to be resumed on switch")
+	}
+
+	if !game.menu.cleaned || !game.level.cleaned {
+		t.Fatal("Expected scenes to be cleaned up")
+	}
+}
+
+func TestRunHeadlessStop(t *testing.T) {
+	game := newTestGame(3)
+	game.engine.RunHeadless(game, 10, 0.1)
+
+	if game.frames != 3 {
+		t.Fatalf("Expected game to stop after 3 frames, got %v", game.frames)
+	}
+}
+
+func TestRunHeadlessDestroysActors(t *testing.T) {
+	game := newTestGame(0)
+	actor := NewActor()
+	destroyed := false
+	game.engine.AddDestroyCallback(actor, func(a *Actor) { destroyed = a == actor })
+	game.engine.RunHeadless(game, 1, 0.1)
+
+	if !destroyed {
+		t.Fatal("Expected destroy callback to be called on cleanup")
+	}
+}
@@ -0,0 +1,1140 @@
+package goga
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/go-gl/gl/v3.2-core/gl"
+	"image"
+	"io"
+	"log"
+	"math"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	gltf_glb_magic      = "glTF"
+	gltf_glb_chunk_json = 0x4E4F534A
+	gltf_glb_chunk_bin  = 0x004E4942
+
+	gltf_mode_triangles      = 4
+	gltf_mode_triangle_strip = 5
+	gltf_mode_triangle_fan   = 6
+
+	// upper limit for accessors without buffer view, as counts are read from file
+	gltf_max_sparse_count = 1 << 24
+)
+
+var (
+	// Number of components of accessor types.
+	gltfTypeSizes = map[string]int{
+		"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16,
+	}
+
+	// Size in bytes of accessor component types.
+	gltfComponentSizes = map[int]int{
+		5120: 1, 5121: 1, 5122: 2, 5123: 2, 5125: 4, 5126: 4,
+	}
+)
+
+// Scene parsed from a glTF file, referring to its root nodes.
+type GltfScene struct {
+	Name  string
+	Nodes []int
+}
+
+// Node of the glTF scene graph.
+// Parent and Mesh are -1 if not set. The transformation is either given by Matrix
+// or by translation, rotation (quaternion x, y, z, w) and scale, if Matrix is nil.
+type GltfNode struct {
+	Name        string
+	Parent      int
+	Children    []int
+	Mesh        int
+	Matrix      *Mat4
+	Translation Vec3
+	Rotation    Vec4
+	Scale       Vec3
+}
+
+// Mesh parsed from a glTF file.
+type GltfMesh struct {
+	Name       string
+	Primitives []GltfPrimitive
+}
+
+// Indexed triangles of a glTF mesh, using one material (-1 if not set).
+// Texture coordinates are 0 if not present, normals are empty if not present.
+type GltfPrimitive struct {
+	Vertices, TexCoords, Normals []float32
+	Indices                      []uint32
+	Material                     int
+}
+
+// Material parsed from a glTF file.
+// BaseColorTexture refers to a texture and is -1 if not set.
+type GltfMaterial struct {
+	Name             string
+	BaseColor        Vec4
+	BaseColorTexture int
+}
+
+// Texture parsed from a glTF file, referring to an image (-1 if not set).
+type GltfTexture struct {
+	Image int
+}
+
+// Image parsed from a glTF file.
+// Images stored within the file (buffer views and data URIs) have their encoded Data set,
+// otherwise URI is the path relative to the glTF file.
+type GltfImage struct {
+	Name     string
+	URI      string
+	MimeType string
+	Data     []byte
+}
+
+// Animation parsed from a glTF file.
+type GltfAnimation struct {
+	Name     string
+	Channels []GltfChannel
+}
+
+// Keyframes animating a property of a node.
+// Path is "translation", "rotation", "scale" or "weights",
+// interpolation is "LINEAR", "STEP" or "CUBICSPLINE".
+// Values contains Components values for each keyframe (three per keyframe for cubic splines).
+type GltfChannel struct {
+	Node          int
+	Path          string
+	Interpolation string
+	Times         []float32
+	Values        []float32
+	Components    int
+}
+
+// Data parsed from a glTF file, without GL objects.
+// Elements refer to each other by their index, like in the file.
+// Scene is the default scene and -1 if the file contains no scenes.
+type GltfData struct {
+	Scene      int
+	Scenes     []GltfScene
+	Nodes      []GltfNode
+	Meshes     []GltfMesh
+	Materials  []GltfMaterial
+	Textures   []GltfTexture
+	Images     []GltfImage
+	Animations []GltfAnimation
+}
+
+// GL buffers of a glTF mesh primitive.
+// The mesh VAO must be prepared by ModelRenderer.
+type GltfMeshBuffers struct {
+	Mesh         *Mesh
+	NormalBuffer *VBO
+}
+
+// glTF file resource.
+// Meshes contains the buffers for each primitive of each mesh. Images contains a texture for each image,
+// images stored within the file are uploaded by the loader, others are resolved by GetGltf().
+type Gltf struct {
+	name string
+	path string
+	ext  string
+
+	Data   *GltfData
+	Meshes [][]GltfMeshBuffers
+	Images []*Tex
+	rgba   []*image.RGBA
+}
+
+// Loads glTF 2.0 files (.gltf and binary .glb) and creates the meshes and embedded textures within the Gltf resource.
+// External buffers are read from the virtual file system relative to the glTF file.
+// Triangle primitives (including strips and fans) are supported, using position, normal and
+// first texture coordinate attributes. Sparse accessors are not supported.
+// Malformed files are reported as *ParseError.
+type GltfLoader struct {
+	VboUsage uint32
+}
+
+type jsonGltf struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	Scene       *int                 `json:"scene"`
+	Scenes      []jsonGltfScene      `json:"scenes"`
+	Nodes       []jsonGltfNode       `json:"nodes"`
+	Meshes      []jsonGltfMesh       `json:"meshes"`
+	Materials   []jsonGltfMaterial   `json:"materials"`
+	Textures    []jsonGltfTexture    `json:"textures"`
+	Images      []jsonGltfImage      `json:"images"`
+	Accessors   []jsonGltfAccessor   `json:"accessors"`
+	BufferViews []jsonGltfBufferView `json:"bufferViews"`
+	Buffers     []jsonGltfBuffer     `json:"buffers"`
+	Animations  []jsonGltfAnimation  `json:"animations"`
+}
+
+type jsonGltfScene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+type jsonGltfNode struct {
+	Name        string    `json:"name"`
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Matrix      []float64 `json:"matrix"`
+	Translation []float64 `json:"translation"`
+	Rotation    []float64 `json:"rotation"`
+	Scale       []float64 `json:"scale"`
+}
+
+type jsonGltfMesh struct {
+	Name       string              `json:"name"`
+	Primitives []jsonGltfPrimitive `json:"primitives"`
+}
+
+type jsonGltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type jsonGltfMaterial struct {
+	Name string `json:"name"`
+	Pbr  struct {
+		BaseColorFactor  []float64 `json:"baseColorFactor"`
+		BaseColorTexture *struct {
+			Index int `json:"index"`
+		} `json:"baseColorTexture"`
+	} `json:"pbrMetallicRoughness"`
+}
+
+type jsonGltfTexture struct {
+	Source *int `json:"source"`
+}
+
+type jsonGltfImage struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+type jsonGltfAccessor struct {
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        json.RawMessage `json:"sparse"`
+}
+
+type jsonGltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type jsonGltfBuffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type jsonGltfAnimation struct {
+	Name     string `json:"name"`
+	Channels []struct {
+		Sampler int `json:"sampler"`
+		Target  struct {
+			Node *int   `json:"node"`
+			Path string `json:"path"`
+		} `json:"target"`
+	} `json:"channels"`
+	Samplers []struct {
+		Input         int    `json:"input"`
+		Output        int    `json:"output"`
+		Interpolation string `json:"interpolation"`
+	} `json:"samplers"`
+}
+
+// Reads the buffers and accessors of a parsed glTF file.
+type gltfReader struct {
+	gltf    *jsonGltf
+	buffers [][]byte
+}
+
+// Parses a glTF 2.0 file, either JSON (.gltf) or binary (.glb).
+// External buffers are read using open, which is passed the URI relative to the glTF file
+// and can be nil if the file does not refer to any.
+// Malformed files are reported as *ParseError.
+func ParseGltf(r io.Reader, open func(string) ([]byte, error)) (*GltfData, error) {
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var bin []byte
+
+	if bytes.HasPrefix(content, []byte(gltf_glb_magic)) {
+		content, bin, err = readGlb(content)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gltf := &jsonGltf{}
+
+	if err := json.Unmarshal(content, gltf); err != nil {
+		return nil, &ParseError{Reason: err.Error()}
+	}
+
+	if !strings.HasPrefix(gltf.Asset.Version, "2.") {
+		return nil, &ParseError{Reason: "Unsupported glTF version " + gltf.Asset.Version}
+	}
+
+	reader := &gltfReader{gltf, make([][]byte, 0, len(gltf.Buffers))}
+
+	if err := reader.readBuffers(bin, open); err != nil {
+		return nil, err
+	}
+
+	data, err := reader.read()
+
+	if err != nil {
+		return nil, &ParseError{Reason: err.Error()}
+	}
+
+	return data, nil
+}
+
+// Returns the JSON and binary chunk of a glb file.
+func readGlb(content []byte) ([]byte, []byte, error) {
+	if len(content) < 12 {
+		return nil, nil, &ParseError{Reason: "Unexpected end of glb header"}
+	}
+
+	if version := binary.LittleEndian.Uint32(content[4:]); version != 2 {
+		return nil, nil, &ParseError{Reason: "Unsupported glb version " + strconv.Itoa(int(version))}
+	}
+
+	length := int(binary.LittleEndian.Uint32(content[8:]))
+
+	if length > len(content) {
+		return nil, nil, &ParseError{Reason: "Unexpected end of glb file"}
+	}
+
+	var jsonChunk, bin []byte
+	offset := 12
+
+	for offset+8 <= length {
+		chunkLength := int(binary.LittleEndian.Uint32(content[offset:]))
+		chunkType := binary.LittleEndian.Uint32(content[offset+4:])
+		offset += 8
+
+		if chunkLength > length-offset {
+			return nil, nil, &ParseError{Reason: "Unexpected end of glb chunk"}
+		}
+
+		chunk := content[offset : offset+chunkLength]
+		offset += chunkLength
+
+		if chunkType == gltf_glb_chunk_json && jsonChunk == nil {
+			jsonChunk = chunk
+		} else if chunkType == gltf_glb_chunk_bin && bin == nil {
+			bin = chunk
+		}
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, &ParseError{Reason: "Missing JSON chunk in glb file"}
+	}
+
+	return jsonChunk, bin, nil
+}
+
+// Reads the buffers from data URIs, the binary glb chunk or external files.
+func (r *gltfReader) readBuffers(bin []byte, open func(string) ([]byte, error)) error {
+	for i, buffer := range r.gltf.Buffers {
+		var data []byte
+		var err error
+
+		if buffer.URI == "" && i == 0 && bin != nil {
+			data = bin
+		} else if buffer.URI == "" {
+			return &ParseError{Reason: "Buffer " + strconv.Itoa(i) + " has no URI"}
+		} else if strings.HasPrefix(buffer.URI, "data:") {
+			data, err = decodeDataURI(buffer.URI)
+
+			if err != nil {
+				return &ParseError{Reason: "Buffer " + strconv.Itoa(i) + ": " + err.Error()}
+			}
+		} else {
+			uri, err := url.PathUnescape(buffer.URI)
+
+			if err != nil || open == nil {
+				return &ParseError{Reason: "Cannot open buffer " + buffer.URI}
+			}
+
+			data, err = open(uri)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(data) < buffer.ByteLength {
+			return &ParseError{Reason: "Buffer " + strconv.Itoa(i) + " is shorter than its byte length"}
+		}
+
+		r.buffers = append(r.buffers, data)
+	}
+
+	return nil
+}
+
+// Decodes a base64 data URI.
+func decodeDataURI(uri string) ([]byte, error) {
+	i := strings.Index(uri, ",")
+
+	if i < 0 || !strings.HasSuffix(uri[:i], ";base64") {
+		return nil, errors.New("Data URI must be base64 encoded")
+	}
+
+	return base64.StdEncoding.DecodeString(uri[i+1:])
+}
+
+// Converts the parsed JSON to GltfData.
+func (r *gltfReader) read() (*GltfData, error) {
+	data := &GltfData{Scene: -1}
+	var err error
+
+	if len(r.gltf.Scenes) > 0 {
+		data.Scene = 0
+	}
+
+	if r.gltf.Scene != nil {
+		if *r.gltf.Scene < 0 || *r.gltf.Scene >= len(r.gltf.Scenes) {
+			return nil, errors.New("Scene out of range")
+		}
+
+		data.Scene = *r.gltf.Scene
+	}
+
+	if data.Scenes, err = r.readScenes(); err != nil {
+		return nil, err
+	}
+
+	if data.Nodes, err = r.readNodes(); err != nil {
+		return nil, err
+	}
+
+	if data.Meshes, err = r.readMeshes(); err != nil {
+		return nil, err
+	}
+
+	if data.Materials, err = r.readMaterials(); err != nil {
+		return nil, err
+	}
+
+	if data.Textures, err = r.readTextures(); err != nil {
+		return nil, err
+	}
+
+	if data.Images, err = r.readImages(); err != nil {
+		return nil, err
+	}
+
+	if data.Animations, err = r.readAnimations(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *gltfReader) readScenes() ([]GltfScene, error) {
+	scenes := make([]GltfScene, 0, len(r.gltf.Scenes))
+
+	for _, scene := range r.gltf.Scenes {
+		for _, node := range scene.Nodes {
+			if node < 0 || node >= len(r.gltf.Nodes) {
+				return nil, errors.New("Scene " + scene.Name + " refers to node out of range")
+			}
+		}
+
+		scenes = append(scenes, GltfScene{scene.Name, scene.Nodes})
+	}
+
+	return scenes, nil
+}
+
+func (r *gltfReader) readNodes() ([]GltfNode, error) {
+	nodes := make([]GltfNode, 0, len(r.gltf.Nodes))
+
+	for _, n := range r.gltf.Nodes {
+		node := GltfNode{Name: n.Name, Parent: -1, Children: n.Children, Mesh: -1, Rotation: Vec4{0, 0, 0, 1}, Scale: Vec3{1, 1, 1}}
+
+		if node.Children == nil {
+			node.Children = make([]int, 0)
+		}
+
+		if n.Mesh != nil {
+			if *n.Mesh < 0 || *n.Mesh >= len(r.gltf.Meshes) {
+				return nil, errors.New("Node " + n.Name + " refers to mesh out of range")
+			}
+
+			node.Mesh = *n.Mesh
+		}
+
+		if len(n.Matrix) == 16 {
+			node.Matrix = &Mat4{}
+			copy(node.Matrix.Values[:], n.Matrix)
+		} else if n.Matrix != nil {
+			return nil, errors.New("Node " + n.Name + " must have 16 matrix values")
+		}
+
+		if len(n.Translation) == 3 {
+			node.Translation = Vec3{n.Translation[0], n.Translation[1], n.Translation[2]}
+		}
+
+		if len(n.Rotation) == 4 {
+			node.Rotation = Vec4{n.Rotation[0], n.Rotation[1], n.Rotation[2], n.Rotation[3]}
+		}
+
+		if len(n.Scale) == 3 {
+			node.Scale = Vec3{n.Scale[0], n.Scale[1], n.Scale[2]}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	for i, node := range nodes {
+		for _, child := range node.Children {
+			if child < 0 || child >= len(nodes) || nodes[child].Parent != -1 || child == i {
+				return nil, errors.New("Node " + node.Name + " has invalid child")
+			}
+
+			nodes[child].Parent = i
+		}
+	}
+
+	// a cycle would loop forever when walking up the hierarchy
+	for i := range nodes {
+		parent := nodes[i].Parent
+
+		for depth := 0; parent != -1; depth++ {
+			if depth > len(nodes) {
+				return nil, errors.New("Node hierarchy contains a cycle")
+			}
+
+			parent = nodes[parent].Parent
+		}
+	}
+
+	return nodes, nil
+}
+
+func (r *gltfReader) readMeshes() ([]GltfMesh, error) {
+	meshes := make([]GltfMesh, 0, len(r.gltf.Meshes))
+
+	for i, m := range r.gltf.Meshes {
+		mesh := GltfMesh{m.Name, make([]GltfPrimitive, 0, len(m.Primitives))}
+
+		for _, p := range m.Primitives {
+			primitive, err := r.readPrimitive(p)
+
+			if err != nil {
+				return nil, errors.New("Mesh " + strconv.Itoa(i) + ": " + err.Error())
+			}
+
+			mesh.Primitives = append(mesh.Primitives, primitive)
+		}
+
+		meshes = append(meshes, mesh)
+	}
+
+	return meshes, nil
+}
+
+func (r *gltfReader) readPrimitive(p jsonGltfPrimitive) (GltfPrimitive, error) {
+	primitive := GltfPrimitive{Material: -1}
+	mode := gltf_mode_triangles
+
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+
+	if mode != gltf_mode_triangles && mode != gltf_mode_triangle_strip && mode != gltf_mode_triangle_fan {
+		return primitive, errors.New("Unsupported primitive mode " + strconv.Itoa(mode))
+	}
+
+	if p.Material != nil {
+		if *p.Material < 0 || *p.Material >= len(r.gltf.Materials) {
+			return primitive, errors.New("Material out of range")
+		}
+
+		primitive.Material = *p.Material
+	}
+
+	position, ok := p.Attributes["POSITION"]
+
+	if !ok {
+		return primitive, errors.New("Primitive has no positions")
+	}
+
+	vertices, err := r.readFloats(position, "VEC3")
+
+	if err != nil {
+		return primitive, err
+	}
+
+	count := len(vertices) / 3
+	primitive.Vertices = vertices
+	primitive.TexCoords = make([]float32, count*2)
+	primitive.Normals = make([]float32, 0)
+
+	if texCoord, ok := p.Attributes["TEXCOORD_0"]; ok {
+		if primitive.TexCoords, err = r.readFloats(texCoord, "VEC2"); err != nil {
+			return primitive, err
+		}
+	}
+
+	if normal, ok := p.Attributes["NORMAL"]; ok {
+		if primitive.Normals, err = r.readFloats(normal, "VEC3"); err != nil {
+			return primitive, err
+		}
+	}
+
+	if len(primitive.TexCoords) != count*2 || (len(primitive.Normals) != 0 && len(primitive.Normals) != count*3) {
+		return primitive, errors.New("Attributes must have the same count")
+	}
+
+	indices := make([]uint32, 0)
+
+	if p.Indices != nil {
+		values, _, err := r.readAccessor(*p.Indices)
+
+		if err != nil {
+			return primitive, err
+		}
+
+		for _, value := range values {
+			if value < 0 || int(value) >= count || value != math.Trunc(value) {
+				return primitive, errors.New("Index out of range")
+			}
+
+			indices = append(indices, uint32(value))
+		}
+	} else {
+		for i := 0; i < count; i++ {
+			indices = append(indices, uint32(i))
+		}
+	}
+
+	primitive.Indices = triangulateGltf(indices, mode)
+
+	return primitive, nil
+}
+
+// Converts triangle strips and fans to triangles.
+func triangulateGltf(indices []uint32, mode int) []uint32 {
+	if mode == gltf_mode_triangles {
+		return indices[:len(indices)-len(indices)%3]
+	}
+
+	triangles := make([]uint32, 0)
+
+	for i := 2; i < len(indices); i++ {
+		if mode == gltf_mode_triangle_fan {
+			triangles = append(triangles, indices[0], indices[i-1], indices[i])
+		} else if i%2 == 0 {
+			triangles = append(triangles, indices[i-2], indices[i-1], indices[i])
+		} else {
+			triangles = append(triangles, indices[i-1], indices[i-2], indices[i])
+		}
+	}
+
+	return triangles
+}
+
+func (r *gltfReader) readMaterials() ([]GltfMaterial, error) {
+	materials := make([]GltfMaterial, 0, len(r.gltf.Materials))
+
+	for _, m := range r.gltf.Materials {
+		material := GltfMaterial{m.Name, Vec4{1, 1, 1, 1}, -1}
+		color := m.Pbr.BaseColorFactor
+
+		if len(color) == 4 {
+			material.BaseColor = Vec4{color[0], color[1], color[2], color[3]}
+		}
+
+		if m.Pbr.BaseColorTexture != nil {
+			if m.Pbr.BaseColorTexture.Index < 0 || m.Pbr.BaseColorTexture.Index >= len(r.gltf.Textures) {
+				return nil, errors.New("Material " + m.Name + " refers to texture out of range")
+			}
+
+			material.BaseColorTexture = m.Pbr.BaseColorTexture.Index
+		}
+
+		materials = append(materials, material)
+	}
+
+	return materials, nil
+}
+
+func (r *gltfReader) readTextures() ([]GltfTexture, error) {
+	textures := make([]GltfTexture, 0, len(r.gltf.Textures))
+
+	for _, t := range r.gltf.Textures {
+		texture := GltfTexture{-1}
+
+		if t.Source != nil {
+			if *t.Source < 0 || *t.Source >= len(r.gltf.Images) {
+				return nil, errors.New("Texture refers to image out of range")
+			}
+
+			texture.Image = *t.Source
+		}
+
+		textures = append(textures, texture)
+	}
+
+	return textures, nil
+}
+
+func (r *gltfReader) readImages() ([]GltfImage, error) {
+	images := make([]GltfImage, 0, len(r.gltf.Images))
+
+	for _, i := range r.gltf.Images {
+		img := GltfImage{Name: i.Name, MimeType: i.MimeType}
+		var err error
+
+		if i.BufferView != nil {
+			img.Data, _, err = r.readBufferView(*i.BufferView)
+		} else if strings.HasPrefix(i.URI, "data:") {
+			img.Data, err = decodeDataURI(i.URI)
+		} else {
+			img.URI, err = url.PathUnescape(i.URI)
+		}
+
+		if err != nil {
+			return nil, errors.New("Image " + i.Name + ": " + err.Error())
+		}
+
+		images = append(images, img)
+	}
+
+	return images, nil
+}
+
+func (r *gltfReader) readAnimations() ([]GltfAnimation, error) {
+	animations := make([]GltfAnimation, 0, len(r.gltf.Animations))
+
+	for _, a := range r.gltf.Animations {
+		animation := GltfAnimation{a.Name, make([]GltfChannel, 0, len(a.Channels))}
+
+		for _, c := range a.Channels {
+			if c.Target.Node == nil {
+				continue
+			}
+
+			if c.Sampler < 0 || c.Sampler >= len(a.Samplers) || *c.Target.Node < 0 || *c.Target.Node >= len(r.gltf.Nodes) {
+				return nil, errors.New("Animation " + a.Name + " has invalid channel")
+			}
+
+			sampler := a.Samplers[c.Sampler]
+			channel := GltfChannel{Node: *c.Target.Node, Path: c.Target.Path, Interpolation: sampler.Interpolation}
+
+			if channel.Interpolation == "" {
+				channel.Interpolation = "LINEAR"
+			}
+
+			var err error
+
+			if channel.Times, err = r.readFloats(sampler.Input, "SCALAR"); err != nil {
+				return nil, errors.New("Animation " + a.Name + ": " + err.Error())
+			}
+
+			if channel.Values, channel.Components, err = r.readFloatsAnyType(sampler.Output); err != nil {
+				return nil, errors.New("Animation " + a.Name + ": " + err.Error())
+			}
+
+			animation.Channels = append(animation.Channels, channel)
+		}
+
+		animations = append(animations, animation)
+	}
+
+	return animations, nil
+}
+
+// Returns the data of a buffer view and its byte stride.
+func (r *gltfReader) readBufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(r.gltf.BufferViews) {
+		return nil, 0, errors.New("Buffer view out of range")
+	}
+
+	view := r.gltf.BufferViews[index]
+
+	if view.Buffer < 0 || view.Buffer >= len(r.buffers) {
+		return nil, 0, errors.New("Buffer out of range")
+	}
+
+	buffer := r.buffers[view.Buffer]
+
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > len(buffer) || view.ByteLength > len(buffer)-view.ByteOffset {
+		return nil, 0, errors.New("Buffer view exceeds buffer")
+	}
+
+	return buffer[view.ByteOffset : view.ByteOffset+view.ByteLength], view.ByteStride, nil
+}
+
+// Reads an accessor of given type as floats.
+func (r *gltfReader) readFloats(index int, accessorType string) ([]float32, error) {
+	if index >= 0 && index < len(r.gltf.Accessors) && r.gltf.Accessors[index].Type != accessorType {
+		return nil, errors.New("Accessor must be of type " + accessorType)
+	}
+
+	values, _, err := r.readFloatsAnyType(index)
+
+	return values, err
+}
+
+// Reads an accessor as floats and returns the number of components.
+func (r *gltfReader) readFloatsAnyType(index int) ([]float32, int, error) {
+	values, components, err := r.readAccessor(index)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	floats := make([]float32, len(values))
+
+	for i, value := range values {
+		floats[i] = float32(value)
+	}
+
+	return floats, components, nil
+}
+
+// Reads all values of an accessor and returns the number of components.
+// Normalized integers are converted to floats.
+func (r *gltfReader) readAccessor(index int) ([]float64, int, error) {
+	if index < 0 || index >= len(r.gltf.Accessors) {
+		return nil, 0, errors.New("Accessor out of range")
+	}
+
+	accessor := r.gltf.Accessors[index]
+	components, ok := gltfTypeSizes[accessor.Type]
+	size, sizeOk := gltfComponentSizes[accessor.ComponentType]
+
+	if !ok || !sizeOk {
+		return nil, 0, errors.New("Unknown accessor type " + accessor.Type + " " + strconv.Itoa(accessor.ComponentType))
+	}
+
+	if accessor.Sparse != nil {
+		return nil, 0, errors.New("Sparse accessors are not supported")
+	}
+
+	if accessor.Count < 0 || accessor.ByteOffset < 0 {
+		return nil, 0, errors.New("Invalid accessor")
+	}
+
+	// all zeros without buffer view
+	if accessor.BufferView == nil {
+		if accessor.Count > gltf_max_sparse_count {
+			return nil, 0, errors.New("Accessor count too large")
+		}
+
+		return make([]float64, accessor.Count*components), components, nil
+	}
+
+	data, stride, err := r.readBufferView(*accessor.BufferView)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if stride <= 0 {
+		stride = components * size
+	}
+
+	// validate before calculating the end, which could overflow otherwise
+	if stride < components*size || stride > len(data) || accessor.ByteOffset > len(data) || accessor.Count > len(data) {
+		return nil, 0, errors.New("Accessor exceeds buffer view")
+	}
+
+	if accessor.Count > 0 && (len(data)-accessor.ByteOffset < components*size ||
+		accessor.Count-1 > (len(data)-accessor.ByteOffset-components*size)/stride) {
+		return nil, 0, errors.New("Accessor exceeds buffer view")
+	}
+
+	values := make([]float64, 0, accessor.Count*components)
+
+	for i := 0; i < accessor.Count; i++ {
+		for c := 0; c < components; c++ {
+			offset := accessor.ByteOffset + i*stride + c*size
+			values = append(values, readGltfComponent(data[offset:], accessor.ComponentType, accessor.Normalized))
+		}
+	}
+
+	return values, components, nil
+}
+
+// Reads a little endian component value.
+func readGltfComponent(data []byte, componentType int, normalized bool) float64 {
+	var value, max float64
+
+	switch componentType {
+	case 5120:
+		value, max = float64(int8(data[0])), 127
+	case 5121:
+		value, max = float64(data[0]), 255
+	case 5122:
+		value, max = float64(int16(binary.LittleEndian.Uint16(data))), 32767
+	case 5123:
+		value, max = float64(binary.LittleEndian.Uint16(data)), 65535
+	case 5125:
+		return float64(binary.LittleEndian.Uint32(data))
+	default:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+	}
+
+	if normalized {
+		return math.Max(value/max, -1)
+	}
+
+	return value
+}
+
+// Returns the local transformation matrix of the node.
+func (n *GltfNode) GetMatrix() *Mat4 {
+	if n.Matrix != nil {
+		return n.Matrix.Copy()
+	}
+
+	x, y, z, w := n.Rotation.X, n.Rotation.Y, n.Rotation.Z, n.Rotation.W
+	rotation := NewMat4(1-2*(y*y+z*z), 2*(x*y+z*w), 2*(x*z-y*w), 0,
+		2*(x*y-z*w), 1-2*(x*x+z*z), 2*(y*z+x*w), 0,
+		2*(x*z+y*w), 2*(y*z-x*w), 1-2*(x*x+y*y), 0,
+		0, 0, 0, 1)
+
+	m := &Mat4{}
+	m.Identity()
+	m.Translate(n.Translation)
+	m.Mult(rotation)
+	m.Scale(n.Scale)
+
+	return m
+}
+
+// Returns the transformation matrix of a node, including the transformations of its parents.
+func (d *GltfData) GetWorldMatrix(node int) *Mat4 {
+	m := d.Nodes[node].GetMatrix()
+
+	for parent := d.Nodes[node].Parent; parent != -1; parent = d.Nodes[parent].Parent {
+		m = MultMat4(d.Nodes[parent].GetMatrix(), m)
+	}
+
+	return m
+}
+
+// Drops the mesh buffers and textures uploaded by the loader.
+// Textures resolved from other resources are not dropped.
+func (g *Gltf) Drop() {
+	for _, mesh := range g.Meshes {
+		for _, buffers := range mesh {
+			buffers.Mesh.Index.Drop()
+			buffers.Mesh.Vertex.Drop()
+			buffers.Mesh.TexCoord.Drop()
+
+			if buffers.Mesh.Vao != nil {
+				buffers.Mesh.Vao.Drop()
+			}
+
+			if buffers.NormalBuffer != nil {
+				buffers.NormalBuffer.Drop()
+			}
+		}
+	}
+
+	for i, tex := range g.Images {
+		if tex != nil && g.Data.Images[i].Data != nil {
+			tex.Drop()
+		}
+	}
+}
+
+// Returns the base color texture of a material or nil, if it has none or it is not loaded.
+func (g *Gltf) GetMaterialTex(material int) *Tex {
+	if material < 0 || material >= len(g.Data.Materials) {
+		return nil
+	}
+
+	texture := g.Data.Materials[material].BaseColorTexture
+
+	if texture < 0 || g.Data.Textures[texture].Image < 0 {
+		return nil
+	}
+
+	return g.Images[g.Data.Textures[texture].Image]
+}
+
+// Returns the name of this resource.
+func (g *Gltf) GetName() string {
+	return g.name
+}
+
+// Sets the name of this resource.
+func (g *Gltf) SetName(name string) {
+	g.name = name
+}
+
+// Returns the path of this resource.
+func (g *Gltf) GetPath() string {
+	return g.path
+}
+
+// Sets the path of this resource.
+func (g *Gltf) SetPath(path string) {
+	g.path = path
+}
+
+// Returns the file extension of this resource.
+func (g *Gltf) GetExt() string {
+	return g.ext
+}
+
+// Sets the file extension of this resource.
+func (g *Gltf) SetExt(ext string) {
+	g.ext = ext
+}
+
+func (g *GltfLoader) Load(r io.Reader) (Res, error) {
+	data, err := g.Decode(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Upload(data)
+}
+
+// Reads and parses the glTF file, including external buffers, and decodes embedded images.
+func (g *GltfLoader) Decode(r io.Reader) (interface{}, error) {
+	var open func(string) ([]byte, error)
+
+	if source, ok := r.(*ResSource); ok {
+		dir := path.Dir(filepath.ToSlash(source.Path))
+		open = func(uri string) ([]byte, error) {
+			return source.ReadFile(path.Join(dir, uri))
+		}
+	}
+
+	data, err := ParseGltf(r, open)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gltf := &Gltf{Data: data, rgba: make([]*image.RGBA, len(data.Images))}
+
+	for i, img := range data.Images {
+		if img.Data == nil {
+			continue
+		}
+
+		decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+
+		if err != nil {
+			return nil, &ParseError{Reason: "Image " + strconv.Itoa(i) + ": " + err.Error()}
+		}
+
+		gltf.rgba[i] = imageToRGBA(decoded)
+	}
+
+	return gltf, nil
+}
+
+// Creates the meshes and textures of parsed glTF data.
+func (g *GltfLoader) Upload(data interface{}) (Res, error) {
+	gltf, ok := data.(*Gltf)
+
+	if !ok {
+		return nil, errors.New("Expected glTF data to upload")
+	}
+
+	gltf.Meshes = make([][]GltfMeshBuffers, 0, len(gltf.Data.Meshes))
+	gltf.Images = make([]*Tex, len(gltf.Data.Images))
+
+	for _, mesh := range gltf.Data.Meshes {
+		buffers := make([]GltfMeshBuffers, 0, len(mesh.Primitives))
+
+		for _, p := range mesh.Primitives {
+			index := refillVBO(nil, gl.ELEMENT_ARRAY_BUFFER, p.Indices, len(p.Indices), g.VboUsage, true)
+			vertex := refillVBO(nil, gl.ARRAY_BUFFER, p.Vertices, len(p.Vertices), g.VboUsage, true)
+			texCoord := refillVBO(nil, gl.ARRAY_BUFFER, p.TexCoords, len(p.TexCoords), g.VboUsage, true)
+			normal := refillVBO(nil, gl.ARRAY_BUFFER, p.Normals, len(p.Normals), g.VboUsage, len(p.Normals) > 0)
+			buffers = append(buffers, GltfMeshBuffers{NewMesh(index, vertex, texCoord), normal})
+		}
+
+		gltf.Meshes = append(gltf.Meshes, buffers)
+	}
+
+	for i, rgba := range gltf.rgba {
+		if rgba == nil {
+			continue
+		}
+
+		tex, err := uploadRGBA(rgba, gl.LINEAR, false)
+
+		if err != nil {
+			// drops the buffers and textures created so far
+			gltf.Drop()
+			gltf.Meshes, gltf.Images = nil, nil
+			return nil, errors.New("Image " + strconv.Itoa(i) + ": " + err.Error())
+		}
+
+		gltf.Images[i] = tex.(*Tex)
+	}
+
+	gltf.rgba = nil
+
+	return gltf, nil
+}
+
+func (g *GltfLoader) Ext() string {
+	return "gltf"
+}
+
+func (g *GltfLoader) Exts() []string {
+	return []string{"gltf", "glb"}
+}
+
+// Resolves the textures of external images of a glTF resource from loaded resources.
+// Images are looked up relative to the glTF resource name, falling back to their file name. Missing resources are logged.
+func (e *Engine) resolveGltfImages(gltf *Gltf) {
+	for i, img := range gltf.Data.Images {
+		if img.Data != nil || img.URI == "" {
+			continue
+		}
+
+		tex, ok := e.getRelativeRes(gltf.GetName(), img.URI).(*Tex)
+
+		if !ok {
+			log.Print("Image " + img.URI + " of " + gltf.GetName() + " not found")
+		}
+
+		gltf.Images[i] = tex
+	}
+}
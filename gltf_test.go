@@ -0,0 +1,131 @@
+package goga
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Returns a buffer holding 4 positions followed by given uint16 indices.
+func newTestGltfBuffer(indices []uint16) []byte {
+	buffer := new(bytes.Buffer)
+	positions := []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0}
+	binary.Write(buffer, binary.LittleEndian, positions)
+	binary.Write(buffer, binary.LittleEndian, indices)
+
+	return buffer.Bytes()
+}
+
+// Returns glTF JSON of a single mesh primitive using given buffer (JSON), mode, index count, nodes (JSON)
+// and byte offset and stride of the position accessor and buffer view.
+func newTestGltfJson(buffer string, mode, indices int, nodes string, offset, stride int) string {
+	return fmt.Sprintf(`{
+		"asset": {"version": "2.0"},
+		"scene": 0,
+		"scenes": [{"nodes": [0]}],
+		"nodes": %v,
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1, "mode": %v}]}],
+		"accessors": [
+			{"bufferView": 0, "byteOffset": %v, "componentType": 5126, "count": 4, "type": "VEC3"},
+			{"bufferView": 1, "componentType": 5123, "count": %v, "type": "SCALAR"}
+		],
+		"bufferViews": [
+			{"buffer": 0, "byteLength": 48, "byteStride": %v},
+			{"buffer": 0, "byteOffset": 48, "byteLength": %v}
+		],
+		"buffers": [%v]
+	}`, nodes, mode, offset, indices, stride, indices*2, buffer)
+}
+
+// Returns a glb file of given JSON and binary chunk.
+func newTestGlb(json string, bin []byte) []byte {
+	for len(json)%4 != 0 {
+		json += " "
+	}
+
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	buffer := new(bytes.Buffer)
+	buffer.WriteString(gltf_glb_magic)
+	binary.Write(buffer, binary.LittleEndian, []uint32{2, uint32(12 + 8 + len(json) + 8 + len(bin))})
+	binary.Write(buffer, binary.LittleEndian, []uint32{uint32(len(json)), gltf_glb_chunk_json})
+	buffer.WriteString(json)
+	binary.Write(buffer, binary.LittleEndian, []uint32{uint32(len(bin)), gltf_glb_chunk_bin})
+	buffer.Write(bin)
+
+	return buffer.Bytes()
+}
+
+func TestParseGltf(t *testing.T) {
+	quad := []uint16{0, 1, 2, 3}
+	dataURI := func(indices []uint16) string {
+		data := newTestGltfBuffer(indices)
+		return fmt.Sprintf(`{"uri": "data:application/octet-stream;base64,%v", "byteLength": %v}`, base64.StdEncoding.EncodeToString(data), len(data))
+	}
+	tree := `[{"children": [1]}, {"mesh": 0}]`
+	tests := []struct {
+		name    string
+		file    []byte
+		indices []uint32
+		err     string
+	}{
+		{"gltf triangles", []byte(newTestGltfJson(dataURI([]uint16{0, 1, 2, 2, 3, 0}), 4, 6, tree, 0, 0)), []uint32{0, 1, 2, 2, 3, 0}, ""},
+		{"gltf external buffer", []byte(newTestGltfJson(`{"uri": "quad%20data.bin", "byteLength": 54}`, 4, 3, tree, 0, 0)), []uint32{0, 1, 2}, ""},
+		{"glb", newTestGlb(newTestGltfJson(`{"byteLength": 56}`, 4, 3, tree, 0, 0), newTestGltfBuffer(quad)), []uint32{0, 1, 2}, ""},
+		{"triangle strip", []byte(newTestGltfJson(dataURI(quad), 5, 4, tree, 0, 0)), []uint32{0, 1, 2, 2, 1, 3}, ""},
+		{"triangle fan", []byte(newTestGltfJson(dataURI(quad), 6, 4, tree, 0, 0)), []uint32{0, 1, 2, 0, 2, 3}, ""},
+		{"interleaved stride", []byte(newTestGltfJson(dataURI(quad), 4, 3, tree, 0, 12)), []uint32{0, 1, 2}, ""},
+		{"node cycle", []byte(newTestGltfJson(dataURI(quad), 4, 3, `[{"children": [1]}, {"children": [0]}]`, 0, 0)), nil, "cycle"},
+		{"node own child", []byte(newTestGltfJson(dataURI(quad), 4, 3, `[{"children": [0]}]`, 0, 0)), nil, "invalid child"},
+		{"index out of range", []byte(newTestGltfJson(dataURI([]uint16{0, 1, 4}), 4, 3, tree, 0, 0)), nil, "Index out of range"},
+		{"too many indices", []byte(newTestGltfJson(dataURI(quad), 4, 5, tree, 0, 0)), nil, "exceeds buffer"},
+		{"large byte offset", []byte(newTestGltfJson(dataURI(quad), 4, 3, tree, 1<<62, 0)), nil, "exceeds buffer"},
+		{"large byte stride", []byte(newTestGltfJson(dataURI(quad), 4, 3, tree, 0, 1<<62)), nil, "exceeds buffer"},
+		{"small byte stride", []byte(newTestGltfJson(dataURI(quad), 4, 3, tree, 0, 4)), nil, "exceeds buffer"},
+		{"unsupported version", []byte(`{"asset": {"version": "1.0"}}`), nil, "Unsupported glTF version"},
+		{"truncated glb", newTestGlb(newTestGltfJson(`{"byteLength": 56}`, 4, 3, tree, 0, 0), nil)[:40], nil, "Unexpected end"},
+	}
+	open := func(uri string) ([]byte, error) {
+		if uri != "quad data.bin" {
+			return nil, errors.New("Not found: " + uri)
+		}
+
+		return newTestGltfBuffer([]uint16{0, 1, 2}), nil
+	}
+
+	for _, test := range tests {
+		data, err := ParseGltf(bytes.NewReader(test.file), open)
+
+		if test.err != "" {
+			if _, ok := err.(*ParseError); !ok || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("%v: expected parse error containing %q, got %v", test.name, test.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+
+		primitive := data.Meshes[0].Primitives[0]
+
+		if !equalUint32(primitive.Indices, test.indices) {
+			t.Fatalf("%v: expected indices %v, got %v", test.name, test.indices, primitive.Indices)
+		}
+
+		if len(primitive.Vertices) != 12 || len(primitive.TexCoords) != 8 || len(primitive.Normals) != 0 {
+			t.Fatalf("%v: unexpected attributes %v", test.name, primitive)
+		}
+
+		if data.Scene != 0 || data.Nodes[1].Parent != 0 || data.Nodes[1].Mesh != 0 || data.Nodes[0].Mesh != -1 {
+			t.Fatalf("%v: unexpected scene graph %v", test.name, data.Nodes)
+		}
+	}
+}
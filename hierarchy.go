@@ -0,0 +1,299 @@
+package goga
+
+import (
+	"math"
+)
+
+// Parent and children of an actor.
+type actorNode struct {
+	actor    *Actor
+	parent   *Actor
+	children []*Actor
+}
+
+// Sets the parent of an actor, or removes it if parent is nil.
+// The Pos2D or Pos3D component of the child becomes relative to the one of the parent,
+// if both have the same position component attached.
+// If keepWorld is true, the local position is changed so that the child stays where it is.
+// Destroying the parent using DestroyActor() destroys all children.
+// Returns false if the parent is a child of actor or the actor itself.
+func SetParent(actor, parent *Actor, keepWorld bool) bool {
+	return defaultEngine.SetParent(actor, parent, keepWorld)
+}
+
+// Sets the parent of an actor, or removes it if parent is nil.
+// See SetParent() for details.
+func (e *Engine) SetParent(actor, parent *Actor, keepWorld bool) bool {
+	if parent != nil && e.isAncestor(actor.GetId(), parent.GetId()) {
+		return false
+	}
+
+	e.unlinkParent(actor.GetId())
+
+	if parent != nil {
+		node := e.getActorNode(actor)
+		node.parent = parent
+		parentNode := e.getActorNode(parent)
+		parentNode.children = append(parentNode.children, actor)
+	}
+
+	e.linkTransform(actor.GetId(), keepWorld)
+
+	return true
+}
+
+// Returns the parent of actor or nil.
+func GetParent(id ActorId) *Actor {
+	return defaultEngine.GetParent(id)
+}
+
+// Returns the parent of actor or nil.
+func (e *Engine) GetParent(id ActorId) *Actor {
+	if node, ok := e.hierarchy[id]; ok {
+		return node.parent
+	}
+
+	return nil
+}
+
+// Returns the children of actor.
+func GetChildren(id ActorId) []*Actor {
+	return defaultEngine.GetChildren(id)
+}
+
+// Returns the children of actor.
+func (e *Engine) GetChildren(id ActorId) []*Actor {
+	children := make([]*Actor, 0)
+
+	if node, ok := e.hierarchy[id]; ok {
+		children = append(children, node.children...)
+	}
+
+	return children
+}
+
+func (e *Engine) getActorNode(actor *Actor) *actorNode {
+	node, ok := e.hierarchy[actor.GetId()]
+
+	if !ok {
+		node = &actorNode{actor: actor, children: make([]*Actor, 0)}
+		e.hierarchy[actor.GetId()] = node
+	}
+
+	return node
+}
+
+// Returns true if actor with ID ancestor is the actor itself or one of its parents.
+func (e *Engine) isAncestor(ancestor, id ActorId) bool {
+	for {
+		if id == ancestor {
+			return true
+		}
+
+		node, ok := e.hierarchy[id]
+
+		if !ok || node.parent == nil {
+			return false
+		}
+
+		id = node.parent.GetId()
+	}
+}
+
+// Removes the link between actor and its parent.
+func (e *Engine) unlinkParent(id ActorId) {
+	node, ok := e.hierarchy[id]
+
+	if !ok || node.parent == nil {
+		return
+	}
+
+	if parentNode, ok := e.hierarchy[node.parent.GetId()]; ok {
+		for i, child := range parentNode.children {
+			if child.GetId() == id {
+				parentNode.children = append(parentNode.children[:i], parentNode.children[i+1:]...)
+				break
+			}
+		}
+
+		e.removeEmptyNode(parentNode)
+	}
+
+	node.parent = nil
+	e.removeEmptyNode(node)
+}
+
+func (e *Engine) removeEmptyNode(node *actorNode) {
+	if node.parent == nil && len(node.children) == 0 {
+		delete(e.hierarchy, node.actor.GetId())
+	}
+}
+
+// Removes actor from hierarchy and returns its children.
+// The children keep their local position.
+func (e *Engine) removeFromHierarchy(id ActorId) []*Actor {
+	node, ok := e.hierarchy[id]
+
+	if !ok {
+		return nil
+	}
+
+	e.unlinkParent(id)
+	children := node.children
+
+	for _, child := range children {
+		if childNode, ok := e.hierarchy[child.GetId()]; ok {
+			childNode.parent = nil
+			e.removeEmptyNode(childNode)
+		}
+
+		e.linkTransform(child.GetId(), false)
+	}
+
+	delete(e.hierarchy, id)
+
+	return children
+}
+
+// Links the position components of actor to the ones of its parent and children.
+// This is called when the hierarchy or components change.
+func (e *Engine) linkTransform(id ActorId, keepWorld bool) {
+	var parentPos2D *Pos2D
+	var parentPos3D *Pos3D
+
+	if parent := e.GetParent(id); parent != nil {
+		parentPos2D = e.components.GetPos2D(parent.GetId())
+		parentPos3D = e.components.GetPos3D(parent.GetId())
+	}
+
+	if pos := e.components.GetPos2D(id); pos != nil {
+		if keepWorld {
+			worldPos, rot, scale := decomposeMat3(pos.calcTransform())
+			pos.SetParent(parentPos2D)
+			pos.setWorldTransform(worldPos, rot, scale)
+		} else {
+			pos.SetParent(parentPos2D)
+		}
+
+		for _, child := range e.GetChildren(id) {
+			if childPos := e.components.GetPos2D(child.GetId()); childPos != nil {
+				childPos.SetParent(pos)
+			}
+		}
+	}
+
+	if pos := e.components.GetPos3D(id); pos != nil {
+		if keepWorld {
+			worldPos, rot, scale := decomposeMat4(pos.calcTransform())
+			pos.SetParent(parentPos3D)
+			pos.setWorldTransform(worldPos, rot, scale)
+		} else {
+			pos.SetParent(parentPos3D)
+		}
+
+		for _, child := range e.GetChildren(id) {
+			if childPos := e.components.GetPos3D(child.GetId()); childPos != nil {
+				childPos.SetParent(pos)
+			}
+		}
+	}
+}
+
+// Unlinks detached position components from the hierarchy.
+func (e *Engine) unlinkTransform(id ActorId, pos2D *Pos2D, pos3D *Pos3D) {
+	if pos2D != nil {
+		pos2D.SetParent(nil)
+	}
+
+	if pos3D != nil {
+		pos3D.SetParent(nil)
+	}
+
+	for _, child := range e.GetChildren(id) {
+		if childPos := e.components.GetPos2D(child.GetId()); childPos != nil && pos2D != nil && childPos.GetParent() == pos2D {
+			childPos.SetParent(nil)
+		}
+
+		if childPos := e.components.GetPos3D(child.GetId()); childPos != nil && pos3D != nil && childPos.GetParent() == pos3D {
+			childPos.SetParent(nil)
+		}
+	}
+}
+
+// Splits a 2D transformation into translation, rotation (in degree) and scale.
+func decomposeMat3(m *Mat3) (Vec2, float64, Vec2) {
+	scale := Vec2{math.Hypot(m.Values[0], m.Values[1]), math.Hypot(m.Values[3], m.Values[4])}
+
+	// mirrored
+	if m.Values[0]*m.Values[4]-m.Values[3]*m.Values[1] < 0 {
+		scale.Y = -scale.Y
+	}
+
+	rot := math.Atan2(m.Values[1], m.Values[0]) * (180 / math.Pi)
+
+	return Vec2{m.Values[6], m.Values[7]}, rot, scale
+}
+
+// Rotates a 2D vector by angle (in degree).
+func rotateVec2(v Vec2, angle float64) Vec2 {
+	angle = angle * (math.Pi / 180)
+	si := math.Sin(angle)
+	co := math.Cos(angle)
+
+	return Vec2{v.X*co - v.Y*si, v.X*si + v.Y*co}
+}
+
+// Splits a 3D transformation into translation, rotation matrix (3x3 column major) and scale.
+func decomposeMat4(m *Mat4) (Vec3, [9]float64, Vec3) {
+	var rot [9]float64
+	scale := Vec3{}
+	axes := []*float64{&scale.X, &scale.Y, &scale.Z}
+
+	for c := 0; c < 3; c++ {
+		length := math.Sqrt(m.Values[c*4]*m.Values[c*4] + m.Values[c*4+1]*m.Values[c*4+1] + m.Values[c*4+2]*m.Values[c*4+2])
+		*axes[c] = length
+
+		for r := 0; r < 3; r++ {
+			if length != 0 {
+				rot[c*3+r] = m.Values[c*4+r] / length
+			}
+		}
+	}
+
+	return Vec3{m.Values[12], m.Values[13], m.Values[14]}, rot, scale
+}
+
+// Multiplies two 3x3 column major rotation matrices.
+func multRot(a, b [9]float64) [9]float64 {
+	var m [9]float64
+
+	for c := 0; c < 3; c++ {
+		for r := 0; r < 3; r++ {
+			m[c*3+r] = a[r]*b[c*3] + a[3+r]*b[c*3+1] + a[6+r]*b[c*3+2]
+		}
+	}
+
+	return m
+}
+
+// Returns the transpose of a 3x3 rotation matrix, which is its inverse.
+func transposeRot(m [9]float64) [9]float64 {
+	return [9]float64{m[0], m[3], m[6], m[1], m[4], m[7], m[2], m[5], m[8]}
+}
+
+// Multiplies a 3x3 column major rotation matrix with vector.
+func multRotVec(m [9]float64, v Vec3) Vec3 {
+	return Vec3{m[0]*v.X + m[3]*v.Y + m[6]*v.Z,
+		m[1]*v.X + m[4]*v.Y + m[7]*v.Z,
+		m[2]*v.X + m[5]*v.Y + m[8]*v.Z}
+}
+
+// Returns the euler angles (in degree) of a rotation matrix,
+// matching the rotation order X, Y, Z used by Pos3D.
+func eulerFromRot(m [9]float64) Vec3 {
+	y := math.Asin(math.Max(-1, math.Min(1, m[6])))
+	x := math.Atan2(-m[7], m[8])
+	z := math.Atan2(-m[3], m[0])
+
+	return Vec3{x * (180 / math.Pi), y * (180 / math.Pi), z * (180 / math.Pi)}
+}
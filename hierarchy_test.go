@@ -0,0 +1,199 @@
+package goga
+
+import (
+	"math"
+	"testing"
+)
+
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 0.000001 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newTestPos2D(pos Vec2, rot float64, scale Vec2) *Pos2D {
+	p := NewPos2D()
+	p.Pos, p.Rot, p.Scale = pos, rot, scale
+	return p
+}
+
+func newTestPos3D(pos, rot, scale Vec3) *Pos3D {
+	p := NewPos3D()
+	p.Pos, p.Rot, p.Scale = pos, rot, scale
+	return p
+}
+
+// Returns the local transformation of a copy of pos without parent.
+func localMat3(pos *Pos2D) Mat3 {
+	p := NewPos2D()
+	p.Pos, p.Rot, p.Scale, p.RotPoint = pos.Pos, pos.Rot, pos.Scale, pos.RotPoint
+	return *p.calcTransform()
+}
+
+func localMat4(pos *Pos3D) Mat4 {
+	p := NewPos3D()
+	p.Pos, p.Rot, p.Scale, p.RotPoint = pos.Pos, pos.Rot, pos.Scale, pos.RotPoint
+	return *p.calcTransform()
+}
+
+func TestSetParent2D(t *testing.T) {
+	for _, keepWorld := range []bool{true, false} {
+		e := NewEngine()
+		parent, child := NewActor(), NewActor()
+		parentPos := newTestPos2D(Vec2{10, 5}, 90, Vec2{2, 2})
+		childPos := newTestPos2D(Vec2{3, 4}, 10, Vec2{1, 0.5})
+		childPos.RotPoint = Vec2{1, 1}
+		e.AttachComponent(parent, parentPos)
+		e.AttachComponent(child, childPos)
+		world := *childPos.calcTransform()
+		local := localMat3(childPos)
+
+		if !e.SetParent(child, parent, keepWorld) || e.GetParent(child.GetId()) != parent || childPos.GetParent() != parentPos {
+			t.Fatalf("keepWorld %v: expected parent to be set", keepWorld)
+		}
+
+		if keepWorld {
+			if !equalValues(childPos.calcTransform().Values[:], world.Values[:]) {
+				t.Fatalf("Expected world transformation to be kept, got %v instead of %v", childPos.calcTransform().Values, world.Values)
+			}
+
+			if _, rot, scale := decomposeMat3(&world); !equalValues([]float64{rot, scale.X, scale.Y}, []float64{10, 1, 0.5}) {
+				t.Fatalf("Expected world transformation to decompose into its values, got %v %v", rot, scale)
+			}
+		} else {
+			parentLocal := localMat3(parentPos)
+
+			if !equalValues(childPos.calcTransform().Values[:], MultMat3(&parentLocal, &local).Values[:]) {
+				t.Fatalf("Expected local transformation to be kept, got %v", childPos.calcTransform().Values)
+			}
+		}
+	}
+}
+
+func TestSetParent3D(t *testing.T) {
+	for _, keepWorld := range []bool{true, false} {
+		e := NewEngine()
+		parent, child := NewActor(), NewActor()
+		parentPos := newTestPos3D(Vec3{1, 2, 3}, Vec3{30, 45, 60}, Vec3{2, 2, 2})
+		childPos := newTestPos3D(Vec3{4, 5, 6}, Vec3{10, 20, 30}, Vec3{1, 2, 3})
+		childPos.RotPoint = Vec3{1, 0, 1}
+		e.AttachComponent(parent, parentPos)
+		e.AttachComponent(child, childPos)
+		world := *childPos.calcTransform()
+		local := localMat4(childPos)
+
+		if !e.SetParent(child, parent, keepWorld) || childPos.GetParent() != parentPos {
+			t.Fatalf("keepWorld %v: expected parent to be set", keepWorld)
+		}
+
+		if keepWorld {
+			if !equalValues(childPos.calcTransform().Values[:], world.Values[:]) {
+				t.Fatalf("Expected world transformation to be kept, got %v instead of %v", childPos.calcTransform().Values, world.Values)
+			}
+
+			_, rot, scale := decomposeMat4(&world)
+
+			if euler := eulerFromRot(rot); !equalValues([]float64{euler.X, euler.Y, euler.Z, scale.X, scale.Y, scale.Z}, []float64{10, 20, 30, 1, 2, 3}) {
+				t.Fatalf("Expected world transformation to decompose into its values, got %v %v", euler, scale)
+			}
+		} else {
+			parentLocal := localMat4(parentPos)
+
+			if !equalValues(childPos.calcTransform().Values[:], MultMat4(&parentLocal, &local).Values[:]) {
+				t.Fatalf("Expected local transformation to be kept, got %v", childPos.calcTransform().Values)
+			}
+		}
+	}
+}
+
+func TestSetParentInvalidatesCache(t *testing.T) {
+	e := NewEngine()
+	parent, other, child := NewActor(), NewActor(), NewActor()
+	parentPos := newTestPos2D(Vec2{10, 0}, 0, Vec2{1, 1})
+	otherPos := newTestPos2D(Vec2{0, 20}, 0, Vec2{1, 1})
+	childPos := newTestPos2D(Vec2{1, 1}, 0, Vec2{1, 1})
+	e.AttachComponent(parent, parentPos)
+	e.AttachComponent(other, otherPos)
+	e.AttachComponent(child, childPos)
+	e.SetParent(child, parent, false)
+
+	if pos := childPos.GetWorldPos(); pos != (Vec2{11, 1}) {
+		t.Fatalf("Expected child to be relative to parent, got %v", pos)
+	}
+
+	parentPos.Pos = Vec2{5, 5}
+
+	if pos := childPos.GetWorldPos(); pos != (Vec2{6, 6}) {
+		t.Fatalf("Expected child to move with parent, got %v", pos)
+	}
+
+	e.SetParent(child, other, false)
+
+	if pos := childPos.GetWorldPos(); pos != (Vec2{1, 21}) || len(e.GetChildren(parent.GetId())) != 0 {
+		t.Fatalf("Expected child to move to new parent, got %v", pos)
+	}
+
+	e.SetParent(child, other, true)
+	e.SetParent(child, nil, true)
+
+	if pos := childPos.GetWorldPos(); pos != (Vec2{1, 21}) || childPos.Pos != (Vec2{1, 21}) || e.GetParent(child.GetId()) != nil {
+		t.Fatalf("Expected child to keep world position when unparented, got %v", pos)
+	}
+
+	otherPos.Pos = Vec2{100, 100}
+
+	if pos := childPos.GetWorldPos(); pos != (Vec2{1, 21}) {
+		t.Fatalf("Expected child not to move with former parent, got %v", pos)
+	}
+}
+
+func TestSetParentRejectsCycles(t *testing.T) {
+	e := NewEngine()
+	grandparent, parent, child := NewActor(), NewActor(), NewActor()
+	e.SetParent(parent, grandparent, false)
+	e.SetParent(child, parent, false)
+
+	if e.SetParent(child, child, false) || e.SetParent(grandparent, child, false) || e.SetParent(parent, child, false) {
+		t.Fatal("Expected cycles to be rejected")
+	}
+
+	if e.GetParent(grandparent.GetId()) != nil || e.GetParent(parent.GetId()) != grandparent || e.GetParent(child.GetId()) != parent {
+		t.Fatal("Expected hierarchy to be kept")
+	}
+}
+
+func TestDestroyActorDestroysChildren(t *testing.T) {
+	e := NewEngine()
+	parent, child, grandchild, sibling := NewActor(), NewActor(), NewActor(), NewActor()
+	e.AttachComponent(grandchild, NewPos2D())
+	e.SetParent(child, parent, false)
+	e.SetParent(grandchild, child, false)
+	e.SetParent(sibling, parent, false)
+	destroyed := make(map[ActorId]bool)
+
+	for _, actor := range []*Actor{parent, child, grandchild, sibling} {
+		e.AddDestroyCallback(actor, func(a *Actor) { destroyed[a.GetId()] = true })
+	}
+
+	e.DestroyActor(parent)
+
+	if len(destroyed) != 4 {
+		t.Fatalf("Expected all actors to be destroyed, got %v", destroyed)
+	}
+
+	if len(e.hierarchy) != 0 {
+		t.Fatalf("Expected hierarchy to be empty, got %v nodes", len(e.hierarchy))
+	}
+
+	if e.components.GetPos2D(grandchild.GetId()) != nil {
+		t.Fatal("Expected components of children to be detached")
+	}
+}
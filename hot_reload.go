@@ -0,0 +1,235 @@
+package goga
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// A resource implementing this interface can be hot reloaded.
+// Swap replaces the contents of the resource by the contents of given resource,
+// which was loaded from the changed file by the same loader.
+// The resource must stay valid, so existing pointers to it can still be used afterwards.
+type Reloadable interface {
+	Res
+	Swap(Res) error
+}
+
+// Modification time and size of a file, used to detect changes.
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// A file watched for changes together with its callbacks.
+type watchedFile struct {
+	state     fileState
+	callbacks []func(string)
+}
+
+// Enables hot reloading of resources.
+// Files of loaded resources are polled for changes using their modification time in given interval.
+// Changed resources implementing Reloadable are reloaded using their loader and
+// swapped in place, afterwards reload callbacks are called. Files are polled on the main thread,
+// so the interval should not be too small when many resources are loaded.
+// Hot reload is disabled when the game stops.
+func EnableHotReload(interval time.Duration) {
+	defaultEngine.EnableHotReload(interval)
+}
+
+// Enables hot reloading of resources.
+// See EnableHotReload() for details.
+func (e *Engine) EnableHotReload(interval time.Duration) {
+	e.reloadInterval = interval
+	log.Printf("Enabled hot reload polling every %v", interval)
+}
+
+// Disables hot reloading of resources.
+func DisableHotReload() {
+	defaultEngine.DisableHotReload()
+}
+
+// Disables hot reloading of resources.
+func (e *Engine) DisableHotReload() {
+	e.reloadInterval = 0
+	e.resStates = make(map[Res]fileState)
+}
+
+// Adds a callback called after the resource with given name was reloaded.
+// Use this to rebuild data derived from the resource.
+func AddReloadCallback(name string, callback func(Res)) {
+	defaultEngine.AddReloadCallback(name, callback)
+}
+
+// Adds a callback called after the resource with given name was reloaded.
+// See AddReloadCallback() for details.
+func (e *Engine) AddReloadCallback(name string, callback func(Res)) {
+	e.reloadCallbacks[name] = append(e.reloadCallbacks[name], callback)
+}
+
+// Removes all reload callbacks for the resource with given name.
+func RemoveReloadCallbacks(name string) {
+	defaultEngine.RemoveReloadCallbacks(name)
+}
+
+// Removes all reload callbacks for the resource with given name.
+func (e *Engine) RemoveReloadCallbacks(name string) {
+	delete(e.reloadCallbacks, name)
+}
+
+// Watches a file of the virtual file system, which is not a resource (like font JSON).
+// The callback is called with the path when the file changed, while hot reload is enabled.
+// Returns an error if the file does not exist.
+func WatchFile(path string, callback func(string)) error {
+	return defaultEngine.WatchFile(path, callback)
+}
+
+// Watches a file of the virtual file system, which is not a resource (like font JSON).
+// See WatchFile() for details.
+func (e *Engine) WatchFile(path string, callback func(string)) error {
+	state, err := e.getFileState(path)
+
+	if err != nil {
+		return err
+	}
+
+	file, ok := e.watchedFiles[path]
+
+	if !ok {
+		file = &watchedFile{state, make([]func(string), 0)}
+		e.watchedFiles[path] = file
+	}
+
+	file.callbacks = append(file.callbacks, callback)
+
+	return nil
+}
+
+// Stops watching a file and removes its callbacks.
+// Returns false if the file was not watched.
+func UnwatchFile(path string) bool {
+	return defaultEngine.UnwatchFile(path)
+}
+
+// Stops watching a file and removes its callbacks.
+// Returns false if the file was not watched.
+func (e *Engine) UnwatchFile(path string) bool {
+	if _, ok := e.watchedFiles[path]; !ok {
+		return false
+	}
+
+	delete(e.watchedFiles, path)
+
+	return true
+}
+
+// Reloads a resource by name from its file and swaps it in place.
+// The resource must implement Reloadable. Options of the resource manifest it was loaded with are applied again.
+// Reload callbacks are called when the resource was reloaded successfully.
+func ReloadRes(name string) error {
+	return defaultEngine.ReloadRes(name)
+}
+
+// Reloads a resource by name from its file and swaps it in place.
+// See ReloadRes() for details.
+func (e *Engine) ReloadRes(name string) error {
+	res := e.GetResByName(name)
+
+	if res == nil {
+		return errors.New("Resource not found: " + name)
+	}
+
+	reloadable, ok := res.(Reloadable)
+
+	if !ok {
+		return errors.New("Resource " + name + " cannot be reloaded")
+	}
+
+	// use the options of the resource manifest the resource was loaded with
+	loaded, err := e.loadFile(res.GetPath(), e.resOptions[res])
+
+	if err != nil {
+		return err
+	}
+
+	if err := reloadable.Swap(loaded); err != nil {
+		dropRes(loaded)
+		return err
+	}
+
+	log.Print("Reloaded resource: " + name)
+
+	for _, callback := range e.reloadCallbacks[name] {
+		callback(res)
+	}
+
+	return nil
+}
+
+// Polls resource files and watched files for changes, if hot reload is enabled and the interval passed.
+// Called at the beginning of each frame.
+func (e *Engine) pollHotReload() {
+	if e.reloadInterval <= 0 || time.Since(e.lastReloadPoll) < e.reloadInterval {
+		return
+	}
+
+	e.lastReloadPoll = time.Now()
+	states := make(map[Res]fileState)
+
+	// reloading may add or remove resources (like reload callbacks do)
+	resources := make([]Res, len(e.resources))
+	copy(resources, e.resources)
+
+	for _, res := range resources {
+		if _, ok := res.(Reloadable); !ok {
+			continue
+		}
+
+		last, known := e.resStates[res]
+		state, err := e.getFileState(res.GetPath())
+
+		// keep the last state while the file is missing, it might be written right now
+		if err != nil {
+			if known {
+				states[res] = last
+			}
+
+			continue
+		}
+
+		states[res] = state
+
+		if known && state != last {
+			if err := e.ReloadRes(res.GetName()); err != nil {
+				log.Print("Error reloading resource " + res.GetName() + ": " + err.Error())
+			}
+		}
+	}
+
+	e.resStates = states
+
+	for path, file := range e.watchedFiles {
+		state, err := e.getFileState(path)
+
+		if err != nil || state == file.state {
+			continue
+		}
+
+		file.state = state
+
+		for _, callback := range file.callbacks {
+			callback(path)
+		}
+	}
+}
+
+// Returns the modification time and size of a file in the virtual file system.
+func (e *Engine) getFileState(path string) (fileState, error) {
+	info, err := e.StatFile(path)
+
+	if err != nil {
+		return fileState{}, err
+	}
+
+	return fileState{info.ModTime().UnixNano(), info.Size()}, nil
+}
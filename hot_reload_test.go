@@ -0,0 +1,76 @@
+package goga
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+type testRes struct {
+	name, path, ext string
+	content         string
+	options         string
+}
+
+func (r *testRes) GetName() string     { return r.name }
+func (r *testRes) SetName(name string) { r.name = name }
+func (r *testRes) GetPath() string     { return r.path }
+func (r *testRes) SetPath(path string) { r.path = path }
+func (r *testRes) GetExt() string      { return r.ext }
+func (r *testRes) SetExt(ext string)   { r.ext = ext }
+
+func (r *testRes) Swap(res Res) error {
+	loaded := res.(*testRes)
+	r.content, r.options = loaded.content, loaded.options
+	return nil
+}
+
+type testResLoader struct{}
+
+func (l *testResLoader) Load(r io.Reader) (Res, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &testRes{content: string(data)}, nil
+}
+
+func (l *testResLoader) LoadWithOptions(r io.Reader, options json.RawMessage) (Res, error) {
+	res, err := l.Load(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.(*testRes).options = string(options)
+	return res, nil
+}
+
+func (l *testResLoader) Ext() string {
+	return "txt"
+}
+
+func TestReloadResWithOptions(t *testing.T) {
+	e := NewEngine()
+	files := fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}}
+	e.MountFS(files)
+	e.AddLoader(&testResLoader{})
+	res, err := e.loadRes("a.txt", "a", json.RawMessage(`{"x":1}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files["a.txt"] = &fstest.MapFile{Data: []byte("b")}
+
+	if err := e.ReloadRes("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded := res.(*testRes); reloaded.content != "b" || reloaded.options != `{"x":1}` {
+		t.Fatalf("Expected resource to be reloaded with options, got %v", *reloaded)
+	}
+}
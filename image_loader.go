@@ -0,0 +1,253 @@
+package goga
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-gl/gl/v3.2-core/gl"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/webp"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"io"
+)
+
+// Loads textures from jpeg (.jpg and .jpeg), gif, bmp, tga and webp files.
+// Use the constructor for a file format (like NewJpegLoader()) to create a loader.
+// Filter and KeepData work the same way as for PngLoader.
+// Gif files are loaded as their first frame, unless Strip is set to true. Then all frames are placed next to
+// each other from left to right, to be used as keyframes (see NewKeyframeSetStrip() and Tex.GetFrames()).
+type ImageLoader struct {
+	Filter   int32
+	KeepData bool
+	Strip    bool
+
+	decode      func(io.Reader) (image.Image, error)
+	decodeStrip func(io.Reader) (image.Image, int, error)
+	exts        []string
+}
+
+// Pixel data of an image strip, decoded by ImageLoader.
+type rgbaStrip struct {
+	*image.RGBA
+	frames int
+}
+
+// Creates a loader for jpeg files (.jpg and .jpeg).
+func NewJpegLoader(filter int32, keepData bool) *ImageLoader {
+	return &ImageLoader{Filter: filter, KeepData: keepData, decode: jpeg.Decode, exts: []string{"jpg", "jpeg"}}
+}
+
+// Creates a loader for gif files.
+// If strip is true, all frames are loaded as strip.
+func NewGifLoader(filter int32, keepData, strip bool) *ImageLoader {
+	return &ImageLoader{Filter: filter, KeepData: keepData, Strip: strip, decode: gif.Decode, decodeStrip: decodeGifStrip, exts: []string{"gif"}}
+}
+
+// Creates a loader for bmp files.
+func NewBmpLoader(filter int32, keepData bool) *ImageLoader {
+	return &ImageLoader{Filter: filter, KeepData: keepData, decode: bmp.Decode, exts: []string{"bmp"}}
+}
+
+// Creates a loader for tga files, uncompressed and run-length encoded.
+func NewTgaLoader(filter int32, keepData bool) *ImageLoader {
+	return &ImageLoader{Filter: filter, KeepData: keepData, decode: decodeTga, exts: []string{"tga"}}
+}
+
+// Creates a loader for lossy and lossless webp files.
+func NewWebpLoader(filter int32, keepData bool) *ImageLoader {
+	return &ImageLoader{Filter: filter, KeepData: keepData, decode: webp.Decode, exts: []string{"webp"}}
+}
+
+type jsonTexOptions struct {
+	Filter   string `json:"filter"`
+	KeepData *bool  `json:"keepData"`
+}
+
+type jsonStripOptions struct {
+	Strip *bool `json:"strip"`
+}
+
+// Decodes an image using given function and converts it to RGBA pixel data.
+// Decoding errors are returned as *ParseError.
+func decodeRGBA(r io.Reader, decode func(io.Reader) (image.Image, error)) (interface{}, error) {
+	img, err := decode(r)
+
+	if err != nil {
+		return nil, &ParseError{Reason: err.Error()}
+	}
+
+	return imageToRGBA(img), nil
+}
+
+// Converts a decoded image to RGBA pixel data.
+func imageToRGBA(img image.Image) *image.RGBA {
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return rgba
+}
+
+// Creates a GL texture from decoded RGBA pixel data, which is either *image.RGBA or an image strip.
+// If keepData is true, the pixel data is stored inside the texture.
+func uploadRGBA(data interface{}, filter int32, keepData bool) (Res, error) {
+	rgba, ok := data.(*image.RGBA)
+	frames := 1
+
+	if strip, isStrip := data.(*rgbaStrip); isStrip {
+		rgba, ok, frames = strip.RGBA, true, strip.frames
+	}
+
+	if !ok {
+		return nil, errors.New("Expected RGBA data to upload")
+	}
+
+	tex := NewTex(gl.TEXTURE_2D)
+	tex.Bind()
+	tex.SetDefaultParams(filter)
+	tex.Texture2D(0,
+		gl.RGBA,
+		int32(rgba.Rect.Size().X),
+		int32(rgba.Rect.Size().Y),
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		rgba.Pix)
+
+	tex.frames = frames
+
+	if keepData {
+		tex.SetRGBA(rgba)
+	}
+
+	return tex, nil
+}
+
+// Returns filter and keepData overridden by texture options from a resource manifest.
+// Options are "filter" ("nearest" or "linear") and "keepData" (bool).
+func texOptions(options json.RawMessage, filter int32, keepData bool) (int32, bool, error) {
+	value := jsonTexOptions{}
+
+	if err := json.Unmarshal(options, &value); err != nil {
+		return 0, false, err
+	}
+
+	switch value.Filter {
+	case "":
+	case "nearest":
+		filter = gl.NEAREST
+	case "linear":
+		filter = gl.LINEAR
+	default:
+		return 0, false, errors.New("Unknown texture filter " + value.Filter)
+	}
+
+	if value.KeepData != nil {
+		keepData = *value.KeepData
+	}
+
+	return filter, keepData, nil
+}
+
+func (i *ImageLoader) Load(r io.Reader) (Res, error) {
+	data, err := i.Decode(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return i.Upload(data)
+}
+
+// Reads and decodes the image file to RGBA pixel data.
+func (i *ImageLoader) Decode(r io.Reader) (interface{}, error) {
+	if !i.Strip || i.decodeStrip == nil {
+		return decodeRGBA(r, i.decode)
+	}
+
+	img, frames, err := i.decodeStrip(r)
+
+	if err != nil {
+		return nil, &ParseError{Reason: err.Error()}
+	}
+
+	return &rgbaStrip{imageToRGBA(img), frames}, nil
+}
+
+// Creates the GL texture from decoded pixel data.
+func (i *ImageLoader) Upload(data interface{}) (Res, error) {
+	return uploadRGBA(data, i.Filter, i.KeepData)
+}
+
+// Loads the texture using options from a resource manifest.
+// Options are the ones of PngLoader.LoadWithOptions() and "strip" (bool) for gif files,
+// which is ignored for other formats.
+func (i *ImageLoader) LoadWithOptions(r io.Reader, options json.RawMessage) (Res, error) {
+	loader := *i
+	strip := jsonStripOptions{}
+	var err error
+
+	if loader.Filter, loader.KeepData, err = texOptions(options, i.Filter, i.KeepData); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(options, &strip); err != nil {
+		return nil, err
+	}
+
+	if strip.Strip != nil {
+		loader.Strip = *strip.Strip
+	}
+
+	return loader.Load(r)
+}
+
+// Returns the first file extension of the image format.
+func (i *ImageLoader) Ext() string {
+	return i.exts[0]
+}
+
+// Returns all file extensions of the image format.
+func (i *ImageLoader) Exts() []string {
+	return i.exts
+}
+
+// Decodes all frames of a gif and places them next to each other.
+// Frames are composed onto the previous ones according to their disposal method.
+// Returns the strip and the number of frames.
+func decodeGifStrip(r io.Reader) (image.Image, int, error) {
+	g, err := gif.DecodeAll(r)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	width, height := g.Config.Width, g.Config.Height
+	strip := image.NewRGBA(image.Rect(0, 0, width*len(g.Image), height))
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(0)
+
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(canvas.Rect)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		draw.Draw(strip, image.Rect(i*width, 0, (i+1)*width, height), canvas, image.Point{0, 0}, draw.Src)
+
+		if disposal == gif.DisposalBackground {
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{0, 0}, draw.Src)
+		} else if disposal == gif.DisposalPrevious {
+			canvas = previous
+		}
+	}
+
+	return strip, len(g.Image), nil
+}
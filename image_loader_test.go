@@ -0,0 +1,72 @@
+package goga
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func newTestGif(t *testing.T, frames int) []byte {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 2), palette)
+		frame.SetColorIndex(i, 0, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+
+	buffer := new(bytes.Buffer)
+
+	if err := gif.EncodeAll(buffer, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func TestImageLoaderGifStrip(t *testing.T) {
+	data := newTestGif(t, 3)
+	decoded, err := NewGifLoader(0, false, true).Decode(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strip, ok := decoded.(*rgbaStrip)
+
+	if !ok || strip.frames != 3 || strip.Rect.Dx() != 12 || strip.Rect.Dy() != 2 {
+		t.Fatalf("Expected strip of 3 frames in 12x2 pixels, got %v", decoded)
+	}
+
+	for i := 0; i < 3; i++ {
+		if r, _, _, _ := strip.At(i*4+i, 0).RGBA(); r == 0 {
+			t.Fatalf("Expected pixel %v of frame %v to be white", i, i)
+		}
+	}
+
+	decoded, err = NewGifLoader(0, false, false).Decode(bytes.NewReader(data))
+
+	if rgba, ok := decoded.(*image.RGBA); err != nil || !ok || rgba.Rect.Dx() != 4 {
+		t.Fatalf("Expected first frame only, got %v (%v)", decoded, err)
+	}
+}
+
+func TestImageLoaderExts(t *testing.T) {
+	e := NewEngine()
+	e.AddLoader(NewJpegLoader(0, false))
+	e.AddLoader(NewTgaLoader(0, false))
+
+	if e.GetLoaderByExt("jpeg") == nil || e.GetLoaderByExt("jpg") == nil || e.GetLoaderByExt("tga") == nil {
+		t.Fatal("Expected loaders to be registered for all extensions")
+	}
+
+	if _, err := NewBmpLoader(0, false).Decode(bytes.NewReader([]byte("invalid"))); err == nil {
+		t.Fatal("Expected error decoding invalid bmp")
+	} else if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("Expected parse error, got %v", err)
+	}
+}
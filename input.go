@@ -4,11 +4,6 @@ import (
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
-var (
-	keyboardListener []KeyboardListener
-	mouseListener    []MouseListener
-)
-
 // Interface for keyboard input events.
 // Implement and register to receive keyboard input.
 type KeyboardListener interface {
@@ -23,50 +18,60 @@ type MouseListener interface {
 	OnMouseScroll(float64, float64)
 }
 
-func initInput(wnd *glfw.Window) {
-	wnd.SetKeyCallback(keyboardCallback)
-	wnd.SetMouseButtonCallback(mouseButtonCallback)
-	wnd.SetCursorPosCallback(mouseMoveCallback)
-	wnd.SetScrollCallback(mouseScrollCallback)
+func (e *Engine) initInput(wnd *glfw.Window) {
+	wnd.SetKeyCallback(e.keyboardCallback)
+	wnd.SetMouseButtonCallback(e.mouseButtonCallback)
+	wnd.SetCursorPosCallback(e.mouseMoveCallback)
+	wnd.SetScrollCallback(e.mouseScrollCallback)
 
-	keyboardListener = make([]KeyboardListener, 0)
-	mouseListener = make([]MouseListener, 0)
+	e.keyboardListener = make([]KeyboardListener, 0)
+	e.mouseListener = make([]MouseListener, 0)
 }
 
-func keyboardCallback(wnd *glfw.Window, key glfw.Key, code int, action glfw.Action, mod glfw.ModifierKey) {
-	for _, listener := range keyboardListener {
+func (e *Engine) keyboardCallback(wnd *glfw.Window, key glfw.Key, code int, action glfw.Action, mod glfw.ModifierKey) {
+	for _, listener := range e.keyboardListener {
 		listener.OnKeyEvent(key, code, action, mod)
 	}
 }
 
-func mouseButtonCallback(wnd *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
-	for _, listener := range mouseListener {
+func (e *Engine) mouseButtonCallback(wnd *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
+	for _, listener := range e.mouseListener {
 		listener.OnMouseButton(button, action, mod)
 	}
 }
 
-func mouseMoveCallback(wnd *glfw.Window, x float64, y float64) {
-	for _, listener := range mouseListener {
-		listener.OnMouseMove(x, float64(viewportHeight)-y)
+func (e *Engine) mouseMoveCallback(wnd *glfw.Window, x float64, y float64) {
+	for _, listener := range e.mouseListener {
+		listener.OnMouseMove(x, float64(e.viewportHeight)-y)
 	}
 }
 
-func mouseScrollCallback(wnd *glfw.Window, x float64, y float64) {
-	for _, listener := range mouseListener {
+func (e *Engine) mouseScrollCallback(wnd *glfw.Window, x float64, y float64) {
+	for _, listener := range e.mouseListener {
 		listener.OnMouseScroll(x, y)
 	}
 }
 
 // Adds a new keyboard listener.
 func AddKeyboardListener(listener KeyboardListener) {
-	keyboardListener = append(keyboardListener, listener)
+	defaultEngine.AddKeyboardListener(listener)
+}
+
+// Adds a new keyboard listener.
+func (e *Engine) AddKeyboardListener(listener KeyboardListener) {
+	e.keyboardListener = append(e.keyboardListener, listener)
 }
 
 // Removes given keyboard listener if found.
 func RemoveKeyboardListener(listener KeyboardListener) {
-	for i, l := range keyboardListener {
+	defaultEngine.RemoveKeyboardListener(listener)
+}
+
+// Removes given keyboard listener if found.
+func (e *Engine) RemoveKeyboardListener(listener KeyboardListener) {
+	for i, l := range e.keyboardListener {
 		if l == listener {
-			keyboardListener = append(keyboardListener[:i], keyboardListener[i+1:]...)
+			e.keyboardListener = append(e.keyboardListener[:i], e.keyboardListener[i+1:]...)
 			return
 		}
 	}
@@ -74,19 +79,34 @@ func RemoveKeyboardListener(listener KeyboardListener) {
 
 // Removes all registered keyboard listeners.
 func RemoveAllKeyboardListener() {
-	keyboardListener = make([]KeyboardListener, 0)
+	defaultEngine.RemoveAllKeyboardListener()
+}
+
+// Removes all registered keyboard listeners.
+func (e *Engine) RemoveAllKeyboardListener() {
+	e.keyboardListener = make([]KeyboardListener, 0)
 }
 
 // Adds a new mouse listener.
 func AddMouseListener(listener MouseListener) {
-	mouseListener = append(mouseListener, listener)
+	defaultEngine.AddMouseListener(listener)
+}
+
+// Adds a new mouse listener.
+func (e *Engine) AddMouseListener(listener MouseListener) {
+	e.mouseListener = append(e.mouseListener, listener)
 }
 
 // Removes given mouse listener if found.
 func RemoveMouseListener(listener MouseListener) {
-	for i, l := range mouseListener {
+	defaultEngine.RemoveMouseListener(listener)
+}
+
+// Removes given mouse listener if found.
+func (e *Engine) RemoveMouseListener(listener MouseListener) {
+	for i, l := range e.mouseListener {
 		if l == listener {
-			mouseListener = append(mouseListener[:i], mouseListener[i+1:]...)
+			e.mouseListener = append(e.mouseListener[:i], e.mouseListener[i+1:]...)
 			return
 		}
 	}
@@ -94,5 +114,10 @@ func RemoveMouseListener(listener MouseListener) {
 
 // Removes all registered mouse listeners.
 func RemoveAllMouseListener() {
-	mouseListener = make([]MouseListener, 0)
+	defaultEngine.RemoveAllMouseListener()
+}
+
+// Removes all registered mouse listeners.
+func (e *Engine) RemoveAllMouseListener() {
+	e.mouseListener = make([]MouseListener, 0)
 }
@@ -49,12 +49,31 @@ func NewKeyframeSet() *KeyframeSet {
 	return set
 }
 
+// Creates a new keyframe set for a horizontal strip of frames with equal width,
+// like textures loaded from gif files with strip enabled (pass Tex.GetFrames(), see ImageLoader).
+func NewKeyframeSetStrip(frames int) *KeyframeSet {
+	set := NewKeyframeSet()
+
+	for i := 0; i < frames; i++ {
+		set.Add(NewKeyframe(Vec2{float64(i) / float64(frames), 0}, Vec2{float64(i+1) / float64(frames), 1}))
+	}
+
+	return set
+}
+
 // Adds a new keyframe to set and returns new length.
 func (s *KeyframeSet) Add(frame *Keyframe) int {
 	s.Keyframes = append(s.Keyframes, *frame)
 	return len(s.Keyframes)
 }
 
+// Deletes the texture coordinate VBOs of all keyframes.
+func (s *KeyframeSet) Drop() {
+	for _, frame := range s.Keyframes {
+		frame.texCoord.Drop()
+	}
+}
+
 // Keyframe animation component.
 // It has a start and an end frame, a play speed and option to loop.
 type KeyframeAnimation struct {
@@ -110,6 +129,7 @@ type KeyframeRenderer struct {
 	Camera *Camera
 
 	sprites       []AnimatedSprite
+	entries       tombstoneIndex
 	index, vertex *VBO
 	vao           *VAO
 }
@@ -131,6 +151,7 @@ func NewKeyframeRenderer(shader *Shader, camera *Camera) *KeyframeRenderer {
 	renderer.Shader = shader
 	renderer.Camera = camera
 	renderer.sprites = make([]AnimatedSprite, 0)
+	renderer.entries = newTombstoneIndex()
 	renderer.index, renderer.vertex, tc = CreateRectMesh(false)
 	tc.Drop() // we don't need that VBO
 	renderer.Size = Vec2{1, 1}
@@ -161,10 +182,8 @@ func (s *KeyframeRenderer) Cleanup() {
 func (s *KeyframeRenderer) Add(actor *Actor, pos *Pos2D, tex *Tex, set *KeyframeSet, animation *KeyframeAnimation) bool {
 	id := actor.GetId()
 
-	for _, sprite := range s.sprites {
-		if id == sprite.Actor.GetId() {
-			return false
-		}
+	if !s.entries.add(id, len(s.sprites)) {
+		return false
 	}
 
 	s.sprites = append(s.sprites, AnimatedSprite{actor, pos, tex, set, animation})
@@ -172,6 +191,17 @@ func (s *KeyframeRenderer) Add(actor *Actor, pos *Pos2D, tex *Tex, set *Keyframe
 	return true
 }
 
+// Returns the query for actors rendered as animated sprites.
+func (s *KeyframeRenderer) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos2DType, TexType, KeyframeSetType, KeyframeAnimationType}}
+}
+
+// Adds animated sprite to the renderer from component store.
+func (s *KeyframeRenderer) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	id := actor.GetId()
+	return s.Add(actor, store.GetPos2D(id), store.GetTex(id), store.GetKeyframeSet(id), store.GetKeyframeAnimation(id))
+}
+
 // Removes animated sprite from renderer.
 func (s *KeyframeRenderer) Remove(actor *Actor) bool {
 	return s.RemoveById(actor.GetId())
@@ -179,24 +209,51 @@ func (s *KeyframeRenderer) Remove(actor *Actor) bool {
 
 // Removes sprite from renderer by ID.
 func (s *KeyframeRenderer) RemoveById(id ActorId) bool {
-	for i, sprite := range s.sprites {
-		if sprite.Actor.GetId() == id {
-			s.sprites = append(s.sprites[:i], s.sprites[i+1:]...)
-			return true
-		}
+	i, ok := s.entries.remove(id)
+
+	if !ok {
+		return false
+	}
+
+	// keep render order, removed entries are compacted on next update
+	// or as soon as more than half of them are removed
+	s.sprites[i] = AnimatedSprite{}
+
+	if s.entries.mostlyRemoved(len(s.sprites)) {
+		s.compact()
 	}
 
-	return false
+	return true
 }
 
 // Removes all animated sprites.
 func (s *KeyframeRenderer) RemoveAll() {
 	s.sprites = make([]AnimatedSprite, 0)
+	s.entries.reset()
 }
 
 // Returns number of sprites.
 func (s *KeyframeRenderer) Len() int {
-	return len(s.sprites)
+	return s.entries.len(len(s.sprites))
+}
+
+// Returns true if the sprite with given actor ID was added to the renderer.
+func (s *KeyframeRenderer) Has(id ActorId) bool {
+	return s.entries.has(id)
+}
+
+// Removes entries left by RemoveById() and updates indices, keeping the order.
+func (s *KeyframeRenderer) compact() {
+	n := s.entries.compact(len(s.sprites),
+		func(i int) *Actor { return s.sprites[i].Actor },
+		func(from, to int) { s.sprites[to] = s.sprites[from] },
+		func(i int) { s.sprites[i] = AnimatedSprite{} })
+	s.sprites = s.sprites[:n]
+}
+
+// Returns true, as this is a render system.
+func (s *KeyframeRenderer) IsRenderSystem() bool {
+	return true
 }
 
 func (s *KeyframeRenderer) GetName() string {
@@ -205,9 +262,11 @@ func (s *KeyframeRenderer) GetName() string {
 
 // Updates animation state and renders sprites.
 func (s *KeyframeRenderer) Update(delta float64) {
+	s.compact()
+
 	// update animation state
 	for _, sprite := range s.sprites {
-		if sprite.KeyframeAnimation == nil {
+		if sprite.Actor == nil || sprite.KeyframeAnimation == nil {
 			continue
 		}
 
@@ -234,7 +293,7 @@ func (s *KeyframeRenderer) Update(delta float64) {
 	s.vao.Bind()
 
 	for _, sprite := range s.sprites {
-		if !sprite.Visible {
+		if sprite.Actor == nil || !sprite.Visible {
 			continue
 		}
 
@@ -242,7 +301,7 @@ func (s *KeyframeRenderer) Update(delta float64) {
 		texCoord.Bind()
 		texCoord.AttribPointer(s.Shader.GetAttribLocation(Default_shader_2D_texcoord_attrib), 2, gl.FLOAT, false, 0)
 
-		s.Shader.SendMat3(Default_shader_2D_model, *sprite.CalcModel())
+		s.Shader.SendMat3(Default_shader_2D_model, *sprite.CalcWorld())
 		sprite.Tex.Bind()
 
 		gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
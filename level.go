@@ -0,0 +1,272 @@
+package goga
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// A level is a set of actors together with their components, stored as JSON.
+// Format:
+//
+//	{
+//	    "actors": [
+//	        {
+//	            "id": 1,
+//	            "name": "player",
+//	            "tags": ["hero"],
+//	            "parent": 0,
+//	            "components": {
+//	                "pos2d": {"pos": {"X": 10, "Y": 20}, ...},
+//	                "tex": "player.png"
+//	            }
+//	        },
+//	        ...
+//	    ]
+//	}
+//
+// IDs are only used to reference parents within the file,
+// loaded actors get new IDs. Name, tags and parent are optional.
+// Components are stored by codec name, see RegisterComponentCodec().
+type jsonLevel struct {
+	Actors []jsonActor `json:"actors"`
+}
+
+type jsonActor struct {
+	Id         ActorId                    `json:"id"`
+	Name       string                     `json:"name,omitempty"`
+	Tags       []string                   `json:"tags,omitempty"`
+	Parent     ActorId                    `json:"parent,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// Saves given actors and their components as level.
+// Components without registered codec are skipped.
+func SaveLevel(w io.Writer, actors []*Actor) error {
+	return defaultEngine.SaveLevel(w, actors)
+}
+
+// Saves given actors and their components as level.
+// Components without registered codec are skipped.
+func (e *Engine) SaveLevel(w io.Writer, actors []*Actor) error {
+	level := jsonLevel{make([]jsonActor, 0, len(actors))}
+
+	for _, actor := range actors {
+		value, err := e.encodeActor(actor)
+
+		if err != nil {
+			return err
+		}
+
+		level.Actors = append(level.Actors, *value)
+	}
+
+	data, err := json.MarshalIndent(level, "", "\t")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// Saves given actors and their components as level to file.
+func SaveLevelFile(path string, actors []*Actor) error {
+	return defaultEngine.SaveLevelFile(path, actors)
+}
+
+// Saves given actors and their components as level to file.
+func (e *Engine) SaveLevelFile(path string, actors []*Actor) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return e.SaveLevel(file, actors)
+}
+
+func (e *Engine) encodeActor(actor *Actor) (*jsonActor, error) {
+	value := &jsonActor{}
+	value.Id = actor.GetId()
+	value.Name = actor.GetActorName()
+	value.Tags = actor.GetTags()
+	value.Components = make(map[string]json.RawMessage)
+
+	if parent := e.GetParent(actor.GetId()); parent != nil {
+		value.Parent = parent.GetId()
+	}
+
+	for _, component := range e.components.GetAll(actor.GetId()) {
+		codec := e.GetComponentCodecByType(ComponentTypeOf(component))
+
+		if codec == nil {
+			log.Printf("No codec for component of type %v, skipping", ComponentTypeOf(component))
+			continue
+		}
+
+		data, err := codec.Encode(e, component)
+
+		if err != nil {
+			return nil, errors.New("Actor " + strconv.FormatUint(uint64(actor.GetId()), 10) + ", " + codec.GetName() + ": " + err.Error())
+		}
+
+		value.Components[codec.GetName()] = data
+	}
+
+	return value, nil
+}
+
+// Loads a level and returns the created actors.
+// The actors get their components attached, so they are added to matching systems.
+// Resources referenced by components must be loaded before.
+// GL objects of decoded components (like the buffers of a TextComponent) are dropped when the actor gets destroyed.
+// If an error occurs, no actor is created and GL objects of components decoded so far are dropped.
+func LoadLevel(r io.Reader) ([]*Actor, error) {
+	return defaultEngine.LoadLevel(r)
+}
+
+// Loads a level and returns the created actors.
+// See LoadLevel() for details.
+func (e *Engine) LoadLevel(r io.Reader) ([]*Actor, error) {
+	level := jsonLevel{}
+
+	if err := json.NewDecoder(r).Decode(&level); err != nil {
+		return nil, err
+	}
+
+	// decode all components first, so that nothing is created on error
+	components := make([][]interface{}, 0, len(level.Actors))
+	ids := make(map[ActorId]int)
+
+	for i, value := range level.Actors {
+		decoded, err := e.decodeComponents(value)
+
+		if err != nil {
+			for _, c := range components {
+				dropComponents(c)
+			}
+
+			return nil, err
+		}
+
+		components = append(components, decoded)
+
+		if value.Id != 0 {
+			ids[value.Id] = i
+		}
+	}
+
+	actors := make([]*Actor, 0, len(level.Actors))
+
+	for i, value := range level.Actors {
+		actor := NewActor()
+		e.AttachComponents(actor, components[i]...)
+		e.dropOnDestroy(actor, components[i])
+
+		if value.Name != "" && !e.SetActorName(actor, value.Name) {
+			log.Print("Actor name used twice, ignoring: " + value.Name)
+		}
+
+		for _, tag := range value.Tags {
+			e.AddActorTag(actor, tag)
+		}
+
+		actors = append(actors, actor)
+	}
+
+	for i, value := range level.Actors {
+		if value.Parent == 0 {
+			continue
+		}
+
+		if parent, ok := ids[value.Parent]; ok {
+			e.SetParent(actors[i], actors[parent], false)
+		} else {
+			log.Printf("Parent %v of actor %v not found in level", value.Parent, value.Id)
+		}
+	}
+
+	return actors, nil
+}
+
+// Loads a level from file in the virtual file system and returns the created actors.
+func LoadLevelFile(path string) ([]*Actor, error) {
+	return defaultEngine.LoadLevelFile(path)
+}
+
+// Loads a level from file in the virtual file system and returns the created actors.
+func (e *Engine) LoadLevelFile(path string) ([]*Actor, error) {
+	file, err := e.OpenFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return e.LoadLevel(file)
+}
+
+func (e *Engine) decodeComponents(value jsonActor) ([]interface{}, error) {
+	components := make([]interface{}, 0, len(value.Components))
+
+	for name, data := range value.Components {
+		codec := e.GetComponentCodecByName(name)
+
+		if codec == nil {
+			dropComponents(components)
+			return nil, errors.New("No codec registered for component: " + name)
+		}
+
+		component, err := codec.Decode(e, data)
+
+		if err != nil {
+			dropComponents(components)
+			return nil, errors.New("Actor " + strconv.FormatUint(uint64(value.Id), 10) + ", " + name + ": " + err.Error())
+		}
+
+		components = append(components, component)
+	}
+
+	return components, nil
+}
+
+// Drops GL objects of given decoded components when the actor gets destroyed.
+// No destroy callback is added if none of them has GL objects.
+func (e *Engine) dropOnDestroy(actor *Actor, components []interface{}) {
+	for _, component := range components {
+		if _, ok := component.(Res); ok {
+			continue
+		}
+
+		if _, ok := component.(Dropable); ok {
+			e.AddDestroyCallback(actor, func(*Actor) {
+				dropComponents(components)
+			})
+
+			return
+		}
+	}
+}
+
+// Drops GL objects created for decoded components, like the buffers of KeyframeSet and TextComponent.
+// Resources (like textures) are owned by the engine and kept.
+func dropComponents(components []interface{}) {
+	for _, component := range components {
+		if _, ok := component.(Res); ok {
+			continue
+		}
+
+		if drop, ok := component.(Dropable); ok {
+			drop.Drop()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package goga
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testDropComponent struct {
+	dropped bool
+}
+
+func (c *testDropComponent) Drop() {
+	c.dropped = true
+}
+
+type testDropCodec struct {
+	decoded []*testDropComponent
+	shared  bool
+}
+
+func (c *testDropCodec) IsShared() bool {
+	return c.shared
+}
+
+func (c *testDropCodec) GetName() string {
+	return "testDrop"
+}
+
+func (c *testDropCodec) GetType() ComponentType {
+	return ComponentTypeOf((*testDropComponent)(nil))
+}
+
+func (c *testDropCodec) Encode(e *Engine, component interface{}) (json.RawMessage, error) {
+	return json.Marshal(nil)
+}
+
+func (c *testDropCodec) Decode(e *Engine, data json.RawMessage) (interface{}, error) {
+	component := &testDropComponent{}
+	c.decoded = append(c.decoded, component)
+	return component, nil
+}
+
+func TestLoadLevelDropsComponentsOnError(t *testing.T) {
+	e := NewEngine()
+	codec := &testDropCodec{}
+	e.RegisterComponentCodec(codec)
+	level := `{"actors": [
+		{"id": 1, "components": {"testDrop": null}},
+		{"id": 2, "components": {"testDrop": null}},
+		{"id": 3, "components": {"pos2d": 42}}
+	]}`
+
+	if _, err := e.LoadLevel(strings.NewReader(level)); err == nil {
+		t.Fatal("Expected level to fail decoding")
+	}
+
+	if len(codec.decoded) != 2 || !codec.decoded[0].dropped || !codec.decoded[1].dropped {
+		t.Fatal("Expected decoded components to be dropped")
+	}
+
+	if len(e.QueryActors(codec.GetType())) != 0 {
+		t.Fatal("Expected no actor to be created")
+	}
+}
+
+func TestLoadLevelDropsComponentsOnDestroy(t *testing.T) {
+	e := NewEngine()
+	codec := &testDropCodec{}
+	e.RegisterComponentCodec(codec)
+	level := `{"actors": [
+		{"id": 1, "components": {"testDrop": null}},
+		{"id": 2, "components": {"pos2d": {}}}
+	]}`
+	actors, err := e.LoadLevel(strings.NewReader(level))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codec.decoded) != 1 || codec.decoded[0].dropped {
+		t.Fatal("Expected decoded component to be kept while the actor exists")
+	}
+
+	e.DestroyActor(actors[0])
+
+	if !codec.decoded[0].dropped {
+		t.Fatal("Expected decoded component to be dropped with the actor")
+	}
+
+	if _, ok := e.destroyCallbacks[actors[1].GetId()]; ok {
+		t.Fatal("Expected no destroy callback for actors without GL objects")
+	}
+}
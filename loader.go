@@ -1,15 +1,12 @@
 package goga
 
 import (
-	"bufio"
+	"encoding/json"
 	"errors"
 	"github.com/go-gl/gl/v3.2-core/gl"
-	"image"
-	"image/draw"
 	"image/png"
-	"os"
-	"strconv"
-	"strings"
+	"io"
+	"unsafe"
 )
 
 // Loads textures from png files.
@@ -21,40 +18,38 @@ type PngLoader struct {
 	KeepData bool
 }
 
-func (p *PngLoader) Load(file string) (Res, error) {
-	// load texture
-	imgFile, err := os.Open(file)
+func (p *PngLoader) Load(r io.Reader) (Res, error) {
+	data, err := p.Decode(r)
 
 	if err != nil {
 		return nil, err
 	}
 
-	img, err := png.Decode(imgFile)
+	return p.Upload(data)
+}
 
-	if err != nil {
-		return nil, err
-	}
+// Reads and decodes the png file to RGBA pixel data.
+func (p *PngLoader) Decode(r io.Reader) (interface{}, error) {
+	return decodeRGBA(r, png.Decode)
+}
 
-	rgba := image.NewRGBA(img.Bounds())
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-
-	// create GL texture
-	tex := NewTex(gl.TEXTURE_2D)
-	tex.Bind()
-	tex.SetDefaultParams(p.Filter)
-	tex.Texture2D(0,
-		gl.RGBA,
-		int32(rgba.Rect.Size().X),
-		int32(rgba.Rect.Size().Y),
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		rgba.Pix)
-
-	if p.KeepData {
-		tex.SetRGBA(rgba)
+// Creates the GL texture from decoded pixel data.
+func (p *PngLoader) Upload(data interface{}) (Res, error) {
+	return uploadRGBA(data, p.Filter, p.KeepData)
+}
+
+// Loads the texture using options from a resource manifest.
+// Options are "filter" ("nearest" or "linear") and "keepData" (bool),
+// which override the settings of this loader for this texture only.
+func (p *PngLoader) LoadWithOptions(r io.Reader, options json.RawMessage) (Res, error) {
+	loader := *p
+	var err error
+
+	if loader.Filter, loader.KeepData, err = texOptions(options, p.Filter, p.KeepData); err != nil {
+		return nil, err
 	}
 
-	return tex, nil
+	return loader.Load(r)
 }
 
 func (p *PngLoader) Ext() string {
@@ -62,22 +57,25 @@ func (p *PngLoader) Ext() string {
 }
 
 // Standford ply file resource.
+// Colors are stored as RGBA, texture coordinates as UV.
 type Ply struct {
 	name string
 	path string
 	ext  string
 
-	firstLine, data, hasVertex, hasTexCoord, hasNormal bool
-	elements, faces                                    int
-	indices                                            []uint32
-	vertices, texCoords, normals                       []float32
+	hasTexCoord, hasNormal, hasColor     bool
+	indices                              []uint32
+	vertices, texCoords, normals, colors []float32
+	vboUsage                             uint32
 
-	IndexBuffer, VertexBuffer, TexCoordBuffer, NormalBuffer *VBO
+	IndexBuffer, VertexBuffer, TexCoordBuffer, NormalBuffer, ColorBuffer *VBO
 }
 
-// Loads ply files and creates VBOs within the Ply resource.
-// The indices must be present as triangles.
-// Expected type is float32. If it fails to parse, it will panic.
+// Loads ascii and binary (little and big endian) ply files and creates VBOs within the Ply resource.
+// Vertex properties are mapped by name: x, y, z for positions, nx, ny, nz for normals,
+// s, t or u, v for texture coordinates and red, green, blue, alpha for colors.
+// Faces with more than three vertices are triangulated as fans.
+// Malformed files are reported as *ParseError.
 type PlyLoader struct {
 	VboUsage uint32
 }
@@ -99,6 +97,70 @@ func (p *Ply) Drop() {
 	if p.NormalBuffer != nil {
 		p.NormalBuffer.Drop()
 	}
+
+	if p.ColorBuffer != nil {
+		p.ColorBuffer.Drop()
+	}
+}
+
+// Replaces the mesh data by given ply, which must be a *Ply.
+// The existing VBOs are refilled instead of replaced,
+// so that pointers and VAOs referring to them stay valid on hot reload.
+func (p *Ply) Swap(res Res) error {
+	ply, ok := res.(*Ply)
+
+	if !ok {
+		return errors.New("Resource " + res.GetName() + " is not a ply")
+	}
+
+	ply.Drop()
+	p.hasTexCoord = ply.hasTexCoord
+	p.hasNormal = ply.hasNormal
+	p.hasColor = ply.hasColor
+	p.indices = ply.indices
+	p.vertices = ply.vertices
+	p.texCoords = ply.texCoords
+	p.normals = ply.normals
+	p.colors = ply.colors
+	p.fillVBOs(ply.vboUsage)
+
+	return nil
+}
+
+// Creates or refills the VBOs from parsed data.
+func (p *Ply) fillVBOs(vboUsage uint32) {
+	p.vboUsage = vboUsage
+	p.IndexBuffer = refillVBO(p.IndexBuffer, gl.ELEMENT_ARRAY_BUFFER, p.indices, len(p.indices), vboUsage, true)
+	p.VertexBuffer = refillVBO(p.VertexBuffer, gl.ARRAY_BUFFER, p.vertices, len(p.vertices), vboUsage, true)
+	p.TexCoordBuffer = refillVBO(p.TexCoordBuffer, gl.ARRAY_BUFFER, p.texCoords, len(p.texCoords), vboUsage, p.hasTexCoord)
+	p.NormalBuffer = refillVBO(p.NormalBuffer, gl.ARRAY_BUFFER, p.normals, len(p.normals), vboUsage, p.hasNormal)
+	p.ColorBuffer = refillVBO(p.ColorBuffer, gl.ARRAY_BUFFER, p.colors, len(p.colors), vboUsage, p.hasColor)
+}
+
+// Fills an existing VBO with data or creates it if nil.
+// Drops the VBO and returns nil if it is not used.
+func refillVBO(vbo *VBO, target uint32, data interface{}, size int, usage uint32, use bool) *VBO {
+	if !use {
+		if vbo != nil {
+			vbo.Drop()
+		}
+
+		return nil
+	}
+
+	if vbo == nil {
+		vbo = NewVBO(target)
+	}
+
+	var ptr unsafe.Pointer
+
+	if size > 0 {
+		ptr = gl.Ptr(data)
+	}
+
+	vbo.Fill(ptr, 4, size, usage)
+
+	return vbo
 }
 
 // Returns the name of this resource.
@@ -131,178 +193,69 @@ func (p *Ply) SetExt(ext string) {
 	p.ext = ext
 }
 
-func (p *PlyLoader) Load(file string) (Res, error) {
-	handle, err := os.Open(file)
-	defer handle.Close()
+func (p *PlyLoader) Load(r io.Reader) (Res, error) {
+	data, err := p.Decode(r)
 
 	if err != nil {
 		return nil, err
 	}
 
-	scanner := bufio.NewScanner(handle)
-	ply := Ply{}
-	ply.indices = make([]uint32, 0)
-	ply.vertices = make([]float32, 0)
-	ply.texCoords = make([]float32, 0)
-	ply.normals = make([]float32, 0)
-
-	for scanner.Scan() {
-		line := strings.ToLower(scanner.Text())
-
-		if ply.data && ply.elements == 0 && ply.faces > 0 {
-			ply.faces--
-
-			if err := ply.parseIndices(line); err != nil {
-				return nil, err
-			}
-		}
-
-		if ply.data && ply.elements > 0 {
-			ply.elements--
-
-			if err := ply.parseData(line); err != nil {
-				return nil, err
-			}
-		}
-
-		if err := ply.parseHeader(line); err != nil {
-			return nil, err
-		}
-
-		ply.firstLine = false
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	index, vertex, texCoord, normal := ply.createVBOs(p.VboUsage)
-	ply.IndexBuffer = index
-	ply.VertexBuffer = vertex
-	ply.TexCoordBuffer = texCoord
-	ply.NormalBuffer = normal
-
-	return &ply, nil
+	return p.Upload(data)
 }
 
-func (p *Ply) createVBOs(vboUsage uint32) (*VBO, *VBO, *VBO, *VBO) {
-	index := NewVBO(gl.ELEMENT_ARRAY_BUFFER)
-	index.Fill(gl.Ptr(p.indices), 4, len(p.indices), vboUsage)
-
-	vertex := NewVBO(gl.ARRAY_BUFFER)
-	vertex.Fill(gl.Ptr(p.vertices), 4, len(p.vertices), vboUsage)
-
-	var texCoord, normal *VBO
-
-	if p.hasTexCoord {
-		texCoord = NewVBO(gl.ARRAY_BUFFER)
-		texCoord.Fill(gl.Ptr(p.texCoords), 4, len(p.texCoords), vboUsage)
-	}
-
-	if p.hasNormal {
-		normal = NewVBO(gl.ARRAY_BUFFER)
-		normal.Fill(gl.Ptr(p.normals), 4, len(p.normals), vboUsage)
-	}
-
-	return index, vertex, texCoord, normal
-}
-
-func (p *Ply) parseHeader(line string) error {
-	if p.firstLine && line != "ply" { // make sure it's a ply file
-		return errors.New("File is not of type ply")
-	} else if strings.Contains(line, "element vertex") { // number of elements
-		elements, err := strconv.Atoi(line[15:])
-
-		if err != nil {
-			return errors.New("Elements could not be parsed")
-		}
-
-		p.elements = elements
-	} else if strings.Contains(line, "property float") {
-		line = line[15:]
-
-		if line == "x" || line == "y" || line == "z" {
-			p.hasVertex = true
-		} else if line == "nx" || line == "ny" || line == "nz" {
-			p.hasNormal = true
-		} else if line == "s" || line == "t" {
-			p.hasTexCoord = true
-		}
-	} else if strings.Contains(line, "element face") { // number of faces
-		faces, err := strconv.Atoi(line[13:])
-
-		if err != nil {
-			return errors.New("Faces could not be parsed")
-		}
+// Reads and parses the ply file.
+func (p *PlyLoader) Decode(r io.Reader) (interface{}, error) {
+	ply, err := parsePly(r)
 
-		p.faces = faces
-	} else if strings.Contains(line, "end_header") {
-		p.data = true
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return ply, nil
 }
 
-func (p *Ply) parseData(line string) error {
-	if !p.hasVertex {
-		return errors.New("ply must have vertex data")
-	}
-
-	parts := strings.Split(line, " ")
-	i := 0
+// Creates the VBOs of parsed ply data.
+func (p *PlyLoader) Upload(data interface{}) (Res, error) {
+	ply, ok := data.(*Ply)
 
-	p.vertices = append(p.vertices, parseFloat32(parts[0]))
-	p.vertices = append(p.vertices, parseFloat32(parts[1]))
-	p.vertices = append(p.vertices, parseFloat32(parts[2]))
-
-	if p.hasNormal {
-		i += 3
-
-		p.normals = append(p.normals, parseFloat32(parts[3]))
-		p.normals = append(p.normals, parseFloat32(parts[4]))
-		p.normals = append(p.normals, parseFloat32(parts[5]))
+	if !ok {
+		return nil, errors.New("Expected ply data to upload")
 	}
 
-	if p.hasTexCoord {
-		p.texCoords = append(p.texCoords, parseFloat32(parts[3+i]))
-		p.texCoords = append(p.texCoords, parseFloat32(parts[4+i]))
-	}
+	ply.fillVBOs(p.VboUsage)
 
-	return nil
+	return ply, nil
 }
 
-func parseFloat32(str string) float32 {
-	float, err := strconv.ParseFloat(str, 32)
-
-	if err != nil {
-		panic(err)
-	}
-
-	return float32(float)
+type jsonPlyOptions struct {
+	Usage string `json:"usage"`
 }
 
-func (p *Ply) parseIndices(line string) error {
-	parts := strings.Split(line, " ")
+// Loads the ply using options from a resource manifest.
+// The option is "usage" ("static", "dynamic" or "stream"),
+// which overrides the VBO usage of this loader for this ply only.
+func (p *PlyLoader) LoadWithOptions(r io.Reader, options json.RawMessage) (Res, error) {
+	value := jsonPlyOptions{}
 
-	if len(parts) != 4 || parts[0] != "3" {
-		return errors.New("Expected triangles for indices")
+	if err := json.Unmarshal(options, &value); err != nil {
+		return nil, err
 	}
 
-	p.indices = append(p.indices, parseUint32(parts[1]))
-	p.indices = append(p.indices, parseUint32(parts[2]))
-	p.indices = append(p.indices, parseUint32(parts[3]))
-
-	return nil
-}
-
-func parseUint32(str string) uint32 {
-	i, err := strconv.Atoi(str)
-
-	if err != nil {
-		panic(err)
+	loader := *p
+
+	switch value.Usage {
+	case "":
+	case "static":
+		loader.VboUsage = gl.STATIC_DRAW
+	case "dynamic":
+		loader.VboUsage = gl.DYNAMIC_DRAW
+	case "stream":
+		loader.VboUsage = gl.STREAM_DRAW
+	default:
+		return nil, errors.New("Unknown VBO usage " + value.Usage)
 	}
 
-	return uint32(i)
+	return loader.Load(r)
 }
 
 func (p *PlyLoader) Ext() string {
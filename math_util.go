@@ -18,3 +18,13 @@ func DistanceVec3(a, b Vec3) float64 {
 func DistanceVec4(a, b Vec4) float64 {
 	return math.Sqrt(float64((a.X-b.X)*(a.X-b.X) + (a.Y-b.Y)*(a.Y-b.Y) + (a.Z-b.Z)*(a.Z-b.Z) + (a.W-b.W)*(a.W-b.W)))
 }
+
+// Returns the linear interpolation between two 2D vectors, a for t = 0 and b for t = 1.
+func LerpVec2(a, b Vec2, t float64) Vec2 {
+	return Vec2{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+}
+
+// Returns the linear interpolation between two 3D vectors, a for t = 0 and b for t = 1.
+func LerpVec3(a, b Vec3, t float64) Vec3 {
+	return Vec3{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t, a.Z + (b.Z-a.Z)*t}
+}
@@ -69,7 +69,8 @@ type ModelRenderer struct {
 	Camera *Camera
 	ortho  bool
 
-	models []Model
+	models  []Model
+	entries tombstoneIndex
 }
 
 // Creates a new model renderer using given shader and camera.
@@ -89,6 +90,7 @@ func NewModelRenderer(shader *Shader, camera *Camera, ortho bool) *ModelRenderer
 	renderer.Camera = camera
 	renderer.ortho = ortho
 	renderer.models = make([]Model, 0)
+	renderer.entries = newTombstoneIndex()
 	renderer.Size = Vec3{1, 1, 1}
 	renderer.Scale = Vec3{1, 1, 1}
 
@@ -101,15 +103,19 @@ func (s *ModelRenderer) Cleanup() {}
 
 // Prepares a model to be rendered by setting up its VAO.
 func (s *ModelRenderer) Prepare(model *Model) {
-	model.Vao = NewVAO()
-	model.Vao.Bind()
+	s.prepareMesh(model.Mesh)
+}
+
+func (s *ModelRenderer) prepareMesh(mesh *Mesh) {
+	mesh.Vao = NewVAO()
+	mesh.Vao.Bind()
 	s.Shader.EnableVertexAttribArrays()
-	model.Index.Bind()
-	model.Vertex.Bind()
-	model.Vertex.AttribPointer(s.Shader.GetAttribLocation(Default_shader_3D_vertex_attrib), 3, gl.FLOAT, false, 0)
-	model.TexCoord.Bind()
-	model.TexCoord.AttribPointer(s.Shader.GetAttribLocation(Default_shader_3D_texcoord_attrib), 2, gl.FLOAT, false, 0)
-	model.Vao.Unbind()
+	mesh.Index.Bind()
+	mesh.Vertex.Bind()
+	mesh.Vertex.AttribPointer(s.Shader.GetAttribLocation(Default_shader_3D_vertex_attrib), 3, gl.FLOAT, false, 0)
+	mesh.TexCoord.Bind()
+	mesh.TexCoord.AttribPointer(s.Shader.GetAttribLocation(Default_shader_3D_texcoord_attrib), 2, gl.FLOAT, false, 0)
+	mesh.Vao.Unbind()
 }
 
 // Adds model to the renderer.
@@ -117,10 +123,8 @@ func (s *ModelRenderer) Prepare(model *Model) {
 func (s *ModelRenderer) Add(actor *Actor, pos *Pos3D, tex *Tex, mesh *Mesh) bool {
 	id := actor.GetId()
 
-	for _, model := range s.models {
-		if id == model.Actor.GetId() {
-			return false
-		}
+	if !s.entries.add(id, len(s.models)) {
+		return false
 	}
 
 	s.models = append(s.models, Model{actor, pos, tex, mesh})
@@ -128,6 +132,24 @@ func (s *ModelRenderer) Add(actor *Actor, pos *Pos3D, tex *Tex, mesh *Mesh) bool
 	return true
 }
 
+// Returns the query for actors rendered as models.
+func (s *ModelRenderer) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos3DType, TexType, MeshType}}
+}
+
+// Adds model to the renderer from component store.
+// The mesh is prepared if it has no VAO yet.
+func (s *ModelRenderer) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	id := actor.GetId()
+	mesh := store.GetMesh(id)
+
+	if mesh.Vao == nil {
+		s.prepareMesh(mesh)
+	}
+
+	return s.Add(actor, store.GetPos3D(id), store.GetTex(id), mesh)
+}
+
 // Removes model from renderer.
 func (s *ModelRenderer) Remove(actor *Actor) bool {
 	return s.RemoveById(actor.GetId())
@@ -135,24 +157,51 @@ func (s *ModelRenderer) Remove(actor *Actor) bool {
 
 // Removes model from renderer by ID.
 func (s *ModelRenderer) RemoveById(id ActorId) bool {
-	for i, model := range s.models {
-		if model.Actor.GetId() == id {
-			s.models = append(s.models[:i], s.models[i+1:]...)
-			return true
-		}
+	i, ok := s.entries.remove(id)
+
+	if !ok {
+		return false
+	}
+
+	// keep render order, removed entries are compacted on next update
+	// or as soon as more than half of them are removed
+	s.models[i] = Model{}
+
+	if s.entries.mostlyRemoved(len(s.models)) {
+		s.compact()
 	}
 
-	return false
+	return true
 }
 
 // Removes all sprites.
 func (s *ModelRenderer) RemoveAll() {
 	s.models = make([]Model, 0)
+	s.entries.reset()
 }
 
 // Returns number of sprites.
 func (s *ModelRenderer) Len() int {
-	return len(s.models)
+	return s.entries.len(len(s.models))
+}
+
+// Returns true if the model with given actor ID was added to the renderer.
+func (s *ModelRenderer) Has(id ActorId) bool {
+	return s.entries.has(id)
+}
+
+// Removes entries left by RemoveById() and updates indices, keeping the order.
+func (s *ModelRenderer) compact() {
+	n := s.entries.compact(len(s.models),
+		func(i int) *Actor { return s.models[i].Actor },
+		func(from, to int) { s.models[to] = s.models[from] },
+		func(i int) { s.models[i] = Model{} })
+	s.models = s.models[:n]
+}
+
+// Returns true, as this is a render system.
+func (s *ModelRenderer) IsRenderSystem() bool {
+	return true
 }
 
 func (s *ModelRenderer) GetName() string {
@@ -161,6 +210,8 @@ func (s *ModelRenderer) GetName() string {
 
 // Render models.
 func (s *ModelRenderer) Update(delta float64) {
+	s.compact()
+
 	s.Shader.Bind()
 	s.Shader.SendUniform1i(Default_shader_3D_tex, 0)
 
@@ -175,11 +226,11 @@ func (s *ModelRenderer) Update(delta float64) {
 	var tid uint32
 
 	for _, model := range s.models {
-		if !model.Visible {
+		if model.Actor == nil || !model.Visible {
 			continue
 		}
 
-		s.Shader.SendMat4(Default_shader_3D_model, *model.CalcModel())
+		s.Shader.SendMat4(Default_shader_3D_model, *model.CalcWorld())
 		model.Vao.Bind()
 
 		// prevent texture switching when not neccessary
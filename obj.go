@@ -0,0 +1,592 @@
+package goga
+
+import (
+	"bufio"
+	"errors"
+	"github.com/go-gl/gl/v3.2-core/gl"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Material parsed from a Wavefront mtl file.
+// The diffuse texture is resolved by GetObj() from DiffuseMap and nil if not loaded.
+type ObjMaterial struct {
+	Name                       string
+	Ambient, Diffuse, Specular Vec3
+	Shininess, Alpha           float64
+	DiffuseMap                 string
+	DiffuseTex                 *Tex
+}
+
+// Range of indices drawn using the same object, group and material.
+type ObjGroup struct {
+	Object, Group, Material string
+	Start, Count            int
+}
+
+// Mesh data parsed from a Wavefront obj file, without GL objects.
+// Each combination of position, texture coordinate and normal used by faces is stored once
+// and referenced by indices. Texture coordinates are 0 for vertices without,
+// normals are empty if the file does not contain any.
+type ObjData struct {
+	Vertices, TexCoords, Normals []float32
+	Indices                      []uint32
+	Groups                       []ObjGroup
+	MaterialLibs                 []string
+}
+
+// Wavefront obj file resource.
+// Materials are resolved by GetObj() from the mtl resources loaded.
+type Obj struct {
+	name string
+	path string
+	ext  string
+
+	Data         *ObjData
+	Materials    map[string]*ObjMaterial
+	Mesh         *Mesh
+	NormalBuffer *VBO
+	vboUsage     uint32
+}
+
+// Loads Wavefront obj files and creates an indexed Mesh within the Obj resource.
+// Supports faces with any number of vertices, which are triangulated as fans,
+// referring to positions, texture coordinates and normals (v, v/vt, v//vn, v/vt/vn),
+// including negative (relative) indices. Objects, groups and materials are stored as ObjGroup.
+// Malformed files are reported as *ParseError.
+type ObjLoader struct {
+	VboUsage uint32
+}
+
+// Wavefront mtl file resource.
+type Mtl struct {
+	name string
+	path string
+	ext  string
+
+	Materials []*ObjMaterial
+}
+
+// Loads Wavefront mtl files, used by obj files.
+// Colors (Ka, Kd, Ks), shininess (Ns), transparency (d, Tr) and the diffuse texture (map_Kd) are read.
+type MtlLoader struct{}
+
+// Position, texture coordinate and normal index of a face vertex, -1 if not set.
+type objVertex [3]int
+
+// Parses a Wavefront obj file.
+// Errors are returned as *ParseError, including the line number.
+func ParseObj(r io.Reader) (*ObjData, error) {
+	data := &ObjData{make([]float32, 0), make([]float32, 0), make([]float32, 0), make([]uint32, 0), make([]ObjGroup, 0), make([]string, 0)}
+	positions := make([][3]float32, 0)
+	texCoords := make([][2]float32, 0)
+	normals := make([][3]float32, 0)
+	vertices := make([]objVertex, 0)
+	index := make(map[objVertex]uint32)
+	group := ObjGroup{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var err error
+
+		switch fields[0] {
+		case "v":
+			var v []float32
+			v, err = parseObjFloats(fields[1:], 3, 3)
+
+			if err == nil {
+				positions = append(positions, [3]float32{v[0], v[1], v[2]})
+			}
+		case "vt":
+			var v []float32
+			v, err = parseObjFloats(fields[1:], 1, 2)
+
+			if err == nil {
+				texCoords = append(texCoords, [2]float32{v[0], v[1]})
+			}
+		case "vn":
+			var v []float32
+			v, err = parseObjFloats(fields[1:], 3, 3)
+
+			if err == nil {
+				normals = append(normals, [3]float32{v[0], v[1], v[2]})
+			}
+		case "f":
+			if len(fields) < 4 {
+				err = errors.New("Face must have at least three vertices")
+				break
+			}
+
+			face := make([]uint32, 0, len(fields)-1)
+
+			for _, field := range fields[1:] {
+				var v objVertex
+				v, err = parseObjVertex(field, len(positions), len(texCoords), len(normals))
+
+				if err != nil {
+					break
+				}
+
+				i, ok := index[v]
+
+				if !ok {
+					i = uint32(len(vertices))
+					index[v] = i
+					vertices = append(vertices, v)
+				}
+
+				face = append(face, i)
+			}
+
+			if err != nil {
+				break
+			}
+
+			for i := 1; i < len(face)-1; i++ {
+				data.Indices = append(data.Indices, face[0], face[i], face[i+1])
+			}
+		case "o", "g", "usemtl":
+			if fields[0] == "usemtl" && len(fields) < 2 {
+				err = errors.New("Material name missing")
+				break
+			}
+
+			name := strings.Join(fields[1:], " ")
+			data.Groups = appendObjGroup(data.Groups, group, len(data.Indices))
+			group.Start = len(data.Indices)
+
+			// groups belong to an object, so a new object starts without group
+			if fields[0] == "o" {
+				group.Object = name
+				group.Group = ""
+			} else if fields[0] == "g" {
+				group.Group = name
+			} else {
+				group.Material = name
+			}
+		case "mtllib":
+			if len(fields) < 2 {
+				err = errors.New("Material library missing")
+				break
+			}
+
+			data.MaterialLibs = append(data.MaterialLibs, fields[1:]...)
+		}
+
+		if err != nil {
+			return nil, &ParseError{Line: line, Reason: err.Error()}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Line: line, Reason: err.Error()}
+	}
+
+	data.Groups = appendObjGroup(data.Groups, group, len(data.Indices))
+	hasNormal := false
+
+	for _, v := range vertices {
+		hasNormal = hasNormal || v[2] >= 0
+	}
+
+	for _, v := range vertices {
+		data.Vertices = append(data.Vertices, positions[v[0]][:]...)
+
+		if v[1] >= 0 {
+			data.TexCoords = append(data.TexCoords, texCoords[v[1]][:]...)
+		} else {
+			data.TexCoords = append(data.TexCoords, 0, 0)
+		}
+
+		if hasNormal && v[2] >= 0 {
+			data.Normals = append(data.Normals, normals[v[2]][:]...)
+		} else if hasNormal {
+			data.Normals = append(data.Normals, 0, 0, 0)
+		}
+	}
+
+	return data, nil
+}
+
+// Adds the group ending at given index, if it contains any indices.
+func appendObjGroup(groups []ObjGroup, group ObjGroup, end int) []ObjGroup {
+	group.Count = end - group.Start
+
+	if group.Count > 0 {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// Parses at least min and at most max floats, additional values are ignored.
+// Missing values up to max are 0.
+func parseObjFloats(fields []string, min, max int) ([]float32, error) {
+	if len(fields) < min {
+		return nil, errors.New("Expected " + strconv.Itoa(min) + " values, got " + strconv.Itoa(len(fields)))
+	}
+
+	values := make([]float32, max)
+
+	for i := 0; i < max && i < len(fields); i++ {
+		value, err := strconv.ParseFloat(fields[i], 32)
+
+		if err != nil {
+			return nil, errors.New("Invalid number " + fields[i])
+		}
+
+		values[i] = float32(value)
+	}
+
+	return values, nil
+}
+
+// Parses a face vertex (v, v/vt, v//vn or v/vt/vn) and resolves its indices to start at 0.
+func parseObjVertex(field string, positions, texCoords, normals int) (objVertex, error) {
+	parts := strings.Split(field, "/")
+	v := objVertex{-1, -1, -1}
+
+	if len(parts) > 3 || parts[0] == "" {
+		return v, errors.New("Invalid face vertex " + field)
+	}
+
+	counts := [3]int{positions, texCoords, normals}
+
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		index, err := strconv.Atoi(part)
+
+		if err != nil {
+			return v, errors.New("Invalid face vertex " + field)
+		}
+
+		if index < 0 {
+			index += counts[i]
+		} else {
+			index--
+		}
+
+		if index < 0 || index >= counts[i] {
+			return v, errors.New("Index out of range in face vertex " + field)
+		}
+
+		v[i] = index
+	}
+
+	return v, nil
+}
+
+// Parses a Wavefront mtl file.
+// Errors are returned as *ParseError, including the line number.
+func ParseMtl(r io.Reader) ([]*ObjMaterial, error) {
+	materials := make([]*ObjMaterial, 0)
+	var material *ObjMaterial
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) < 2 {
+				return nil, &ParseError{Line: line, Reason: "Material without name"}
+			}
+
+			material = &ObjMaterial{Name: strings.Join(fields[1:], " "), Diffuse: Vec3{1, 1, 1}, Alpha: 1}
+			materials = append(materials, material)
+			continue
+		}
+
+		if material == nil {
+			continue
+		}
+
+		var v []float32
+		var err error
+
+		switch fields[0] {
+		case "Ka", "Kd", "Ks":
+			v, err = parseObjFloats(fields[1:], 3, 3)
+
+			if err == nil {
+				color := Vec3{float64(v[0]), float64(v[1]), float64(v[2])}
+
+				if fields[0] == "Ka" {
+					material.Ambient = color
+				} else if fields[0] == "Kd" {
+					material.Diffuse = color
+				} else {
+					material.Specular = color
+				}
+			}
+		case "Ns":
+			v, err = parseObjFloats(fields[1:], 1, 1)
+
+			if err == nil {
+				material.Shininess = float64(v[0])
+			}
+		case "d", "Tr":
+			v, err = parseObjFloats(fields[1:], 1, 1)
+
+			if err == nil && fields[0] == "d" {
+				material.Alpha = float64(v[0])
+			} else if err == nil {
+				material.Alpha = 1 - float64(v[0])
+			}
+		case "map_Kd":
+			// options like -s come first, the file name is last
+			if len(fields) < 2 {
+				err = errors.New("Texture map without file")
+			} else {
+				material.DiffuseMap = fields[len(fields)-1]
+			}
+		}
+
+		if err != nil {
+			return nil, &ParseError{Line: line, Reason: err.Error()}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Line: line, Reason: err.Error()}
+	}
+
+	return materials, nil
+}
+
+// Drops contained GL buffers and the VAO of the mesh, if created.
+func (o *Obj) Drop() {
+	if o.Mesh != nil {
+		o.Mesh.Index.Drop()
+		o.Mesh.Vertex.Drop()
+		o.Mesh.TexCoord.Drop()
+
+		if o.Mesh.Vao != nil {
+			o.Mesh.Vao.Drop()
+		}
+	}
+
+	if o.NormalBuffer != nil {
+		o.NormalBuffer.Drop()
+	}
+}
+
+// Replaces the mesh data by given obj, which must be a *Obj.
+// The existing VBOs are refilled instead of replaced,
+// so that the mesh and its VAO stay valid on hot reload.
+func (o *Obj) Swap(res Res) error {
+	obj, ok := res.(*Obj)
+
+	if !ok {
+		return errors.New("Resource " + res.GetName() + " is not an obj")
+	}
+
+	obj.Drop()
+	o.Data = obj.Data
+	o.Materials = nil
+	o.fillVBOs(obj.vboUsage)
+
+	return nil
+}
+
+// Creates or refills the mesh and normal buffer from parsed data.
+func (o *Obj) fillVBOs(vboUsage uint32) {
+	o.vboUsage = vboUsage
+	data := o.Data
+
+	if o.Mesh == nil {
+		o.Mesh = &Mesh{}
+	}
+
+	o.Mesh.Index = refillVBO(o.Mesh.Index, gl.ELEMENT_ARRAY_BUFFER, data.Indices, len(data.Indices), vboUsage, true)
+	o.Mesh.Vertex = refillVBO(o.Mesh.Vertex, gl.ARRAY_BUFFER, data.Vertices, len(data.Vertices), vboUsage, true)
+	o.Mesh.TexCoord = refillVBO(o.Mesh.TexCoord, gl.ARRAY_BUFFER, data.TexCoords, len(data.TexCoords), vboUsage, true)
+	o.NormalBuffer = refillVBO(o.NormalBuffer, gl.ARRAY_BUFFER, data.Normals, len(data.Normals), vboUsage, len(data.Normals) > 0)
+}
+
+// Returns the name of this resource.
+func (o *Obj) GetName() string {
+	return o.name
+}
+
+// Sets the name of this resource.
+func (o *Obj) SetName(name string) {
+	o.name = name
+}
+
+// Returns the path of this resource.
+func (o *Obj) GetPath() string {
+	return o.path
+}
+
+// Sets the path of this resource.
+func (o *Obj) SetPath(path string) {
+	o.path = path
+}
+
+// Returns the file extension of this resource.
+func (o *Obj) GetExt() string {
+	return o.ext
+}
+
+// Sets the file extension of this resource.
+func (o *Obj) SetExt(ext string) {
+	o.ext = ext
+}
+
+func (o *ObjLoader) Load(r io.Reader) (Res, error) {
+	data, err := o.Decode(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Upload(data)
+}
+
+// Reads and parses the obj file.
+func (o *ObjLoader) Decode(r io.Reader) (interface{}, error) {
+	data, err := ParseObj(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Obj{Data: data}, nil
+}
+
+// Creates the mesh of parsed obj data.
+func (o *ObjLoader) Upload(data interface{}) (Res, error) {
+	obj, ok := data.(*Obj)
+
+	if !ok {
+		return nil, errors.New("Expected obj data to upload")
+	}
+
+	obj.fillVBOs(o.VboUsage)
+
+	return obj, nil
+}
+
+func (o *ObjLoader) Ext() string {
+	return "obj"
+}
+
+// Replaces the materials by given mtl, which must be a *Mtl.
+// Obj resources pick up the new materials on the next call to GetObj().
+func (m *Mtl) Swap(res Res) error {
+	mtl, ok := res.(*Mtl)
+
+	if !ok {
+		return errors.New("Resource " + res.GetName() + " is not a mtl")
+	}
+
+	m.Materials = mtl.Materials
+
+	return nil
+}
+
+// Returns the name of this resource.
+func (m *Mtl) GetName() string {
+	return m.name
+}
+
+// Sets the name of this resource.
+func (m *Mtl) SetName(name string) {
+	m.name = name
+}
+
+// Returns the path of this resource.
+func (m *Mtl) GetPath() string {
+	return m.path
+}
+
+// Sets the path of this resource.
+func (m *Mtl) SetPath(path string) {
+	m.path = path
+}
+
+// Returns the file extension of this resource.
+func (m *Mtl) GetExt() string {
+	return m.ext
+}
+
+// Sets the file extension of this resource.
+func (m *Mtl) SetExt(ext string) {
+	m.ext = ext
+}
+
+func (m *MtlLoader) Load(r io.Reader) (Res, error) {
+	materials, err := ParseMtl(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mtl{Materials: materials}, nil
+}
+
+func (m *MtlLoader) Ext() string {
+	return "mtl"
+}
+
+// Resolves the materials of an obj and their diffuse textures from loaded resources.
+// Material libraries are looked up relative to the obj resource name, textures relative to the mtl,
+// falling back to their file name. Missing resources are logged.
+func (e *Engine) resolveObjMaterials(obj *Obj) {
+	obj.Materials = make(map[string]*ObjMaterial)
+
+	for _, lib := range obj.Data.MaterialLibs {
+		mtl, ok := e.getRelativeRes(obj.GetName(), lib).(*Mtl)
+
+		if !ok {
+			log.Print("Material library " + lib + " of " + obj.GetName() + " not found")
+			continue
+		}
+
+		for _, material := range mtl.Materials {
+			if material.DiffuseMap != "" {
+				tex, ok := e.getRelativeRes(mtl.GetName(), material.DiffuseMap).(*Tex)
+
+				if !ok {
+					log.Print("Texture " + material.DiffuseMap + " of " + mtl.GetName() + " not found")
+				}
+
+				material.DiffuseTex = tex
+			}
+
+			obj.Materials[material.Name] = material
+		}
+	}
+}
+
+// Returns the resource named relative to the directory of base or by its file name, or nil if not found.
+func (e *Engine) getRelativeRes(base, name string) Res {
+	name = filepath.ToSlash(name)
+
+	if res := e.GetResByName(path.Join(path.Dir(base), name)); res != nil {
+		return res
+	}
+
+	return e.GetResByName(path.Base(name))
+}
@@ -0,0 +1,151 @@
+package goga
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseObj(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       string
+		indices   []uint32
+		vertices  int
+		texCoords []float32
+		normals   int
+		groups    []ObjGroup
+	}{
+		{"positions only",
+			"v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3",
+			[]uint32{0, 1, 2}, 3, []float32{0, 0, 0, 0, 0, 0}, 0,
+			[]ObjGroup{{Start: 0, Count: 3}}},
+		{"mixed v/vt/vn",
+			"v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 0.5 1\nvn 0 0 1\nf 1/1/1 2//1 3",
+			[]uint32{0, 1, 2}, 3, []float32{0.5, 1, 0, 0, 0, 0}, 9,
+			[]ObjGroup{{Start: 0, Count: 3}}},
+		{"negative indices",
+			"v 0 0 0\nv 1 0 0\nv 0 1 0\nvt 1 1\nf -3/-1 -2/-1 -1/-1",
+			[]uint32{0, 1, 2}, 3, []float32{1, 1, 1, 1, 1, 1}, 0,
+			[]ObjGroup{{Start: 0, Count: 3}}},
+		{"n-gon fan",
+			"v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv -1 1 0\nf 1 2 3 4 5",
+			[]uint32{0, 1, 2, 0, 2, 3, 0, 3, 4}, 5, nil, 0,
+			[]ObjGroup{{Start: 0, Count: 9}}},
+		{"dedup shared vertices",
+			"v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nvt 1 1\nf 1 2 3\nf 1 3 4\nf 1/1 3 4",
+			[]uint32{0, 1, 2, 0, 2, 3, 4, 2, 3}, 5, nil, 0,
+			[]ObjGroup{{Start: 0, Count: 9}}},
+		{"groups",
+			"v 0 0 0\nv 1 0 0\nv 0 1 0\no a\ng g1\nusemtl m\nf 1 2 3\no b\nf 1 2 3\ng g2\nusemtl n\nf 3 2 1",
+			[]uint32{0, 1, 2, 0, 1, 2, 2, 1, 0}, 3, nil, 0,
+			[]ObjGroup{{"a", "g1", "m", 0, 3}, {"b", "", "m", 3, 3}, {"b", "g2", "n", 6, 3}}},
+	}
+
+	for _, test := range tests {
+		data, err := ParseObj(strings.NewReader(test.obj))
+
+		if err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+
+		if !equalUint32(data.Indices, test.indices) || len(data.Vertices) != test.vertices*3 || len(data.Normals) != test.normals {
+			t.Fatalf("%v: unexpected indices %v, vertices %v or normals %v", test.name, data.Indices, data.Vertices, data.Normals)
+		}
+
+		if test.texCoords != nil && !equalFloat32(data.TexCoords, test.texCoords) {
+			t.Fatalf("%v: unexpected texture coordinates %v", test.name, data.TexCoords)
+		}
+
+		if len(data.Groups) != len(test.groups) {
+			t.Fatalf("%v: expected %v groups, got %v", test.name, len(test.groups), data.Groups)
+		}
+
+		for i, group := range test.groups {
+			if data.Groups[i] != group {
+				t.Fatalf("%v: expected group %v, got %v", test.name, group, data.Groups[i])
+			}
+		}
+	}
+}
+
+func TestParseObjErrors(t *testing.T) {
+	tests := []struct {
+		obj  string
+		line int
+	}{
+		{"v 0 0\n", 1},
+		{"v 0 0 0\nf 1 2\n", 2},
+		{"v 0 0 0\nv 0 0 0\nv 0 0 0\nf 1 2 4\n", 4},
+		{"v 0 0 0\nv 0 0 0\nv 0 0 0\nf 1 2 -4\n", 4},
+		{"v 0 0 0\nv 0 0 0\nv 0 0 0\nf 1/x 2 3\n", 4},
+		{"v 0 0 0\nv 0 0 0\nv 0 0 0\nf 1/0 2 3\n", 4},
+		{"usemtl\n", 1},
+		{"# comment\nmtllib\n", 2},
+	}
+
+	for _, test := range tests {
+		_, err := ParseObj(strings.NewReader(test.obj))
+		parseErr, ok := err.(*ParseError)
+
+		if !ok || parseErr.Line != test.line {
+			t.Fatalf("Expected parse error in line %v for %q, got %v", test.line, test.obj, err)
+		}
+	}
+}
+
+func TestParseMtl(t *testing.T) {
+	mtl := "Kd 0 0 0\nnewmtl red\nKd 1 0 0\nNs 10\nd 0.5\nmap_Kd -s 1 1 1 red.png\nnewmtl blue\nKd 0 0 1\nTr 0.25\n"
+	materials, err := ParseMtl(strings.NewReader(mtl))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(materials) != 2 {
+		t.Fatalf("Expected 2 materials, got %v", len(materials))
+	}
+
+	red, blue := materials[0], materials[1]
+
+	if red.Name != "red" || red.Diffuse != (Vec3{1, 0, 0}) || red.Shininess != 10 || red.Alpha != 0.5 || red.DiffuseMap != "red.png" {
+		t.Fatalf("Unexpected material %v", *red)
+	}
+
+	if blue.Name != "blue" || blue.Diffuse != (Vec3{0, 0, 1}) || blue.Alpha != 0.75 || blue.DiffuseMap != "" {
+		t.Fatalf("Unexpected material %v", *blue)
+	}
+
+	for _, invalid := range []string{"newmtl\n", "newmtl a\nKd 1\n", "newmtl a\nmap_Kd\n"} {
+		if _, err := ParseMtl(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func equalUint32(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalFloat32(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
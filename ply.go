@@ -0,0 +1,538 @@
+package goga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	ply_format_ascii = iota
+	ply_format_binary_le
+	ply_format_binary_be
+
+	// upper limit for preallocation, as counts are read from file
+	ply_max_prealloc = 1 << 16
+	ply_max_list     = 1 << 16
+)
+
+var (
+	// Ply types mapped to their size, including the alternative names.
+	plyTypes = map[string]int{
+		"char": 1, "uchar": 1, "short": 2, "ushort": 2, "int": 4, "uint": 4, "float": 4, "double": 8,
+		"int8": 1, "uint8": 1, "int16": 2, "uint16": 2, "int32": 4, "uint32": 4, "float32": 4, "float64": 8,
+	}
+
+	// Vertex properties mapped to their index within plyVertex.
+	plyVertexProperties = map[string]int{
+		"x": 0, "y": 1, "z": 2,
+		"nx": 3, "ny": 4, "nz": 5,
+		"s": 6, "t": 7, "u": 6, "v": 7, "texture_u": 6, "texture_v": 7, "texture_s": 6, "texture_t": 7,
+		"red": 8, "green": 9, "blue": 10, "alpha": 11,
+	}
+)
+
+// Position, normal, texture coordinate and color of a vertex.
+type plyVertex [12]float64
+
+type plyProperty struct {
+	name      string
+	valueType string
+	countType string
+	list      bool
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// Reads the header and values of ply elements from ascii or binary data.
+type plyReader struct {
+	reader *bufio.Reader
+	format int
+	order  binary.ByteOrder
+	line   int
+	data   bool
+	fields []string
+	buffer [8]byte
+}
+
+// Parses a ply file.
+func parsePly(r io.Reader) (*Ply, error) {
+	reader := &plyReader{reader: bufio.NewReader(r)}
+	elements, err := reader.readHeader()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ply := &Ply{}
+	ply.indices = make([]uint32, 0)
+	ply.vertices = make([]float32, 0)
+	ply.texCoords = make([]float32, 0)
+	ply.normals = make([]float32, 0)
+	ply.colors = make([]float32, 0)
+	hasVertex := false
+
+	for _, element := range elements {
+		if element.name == "vertex" {
+			hasVertex = true
+			err = ply.readVertices(reader, element)
+		} else if element.name == "face" {
+			err = ply.readFaces(reader, element)
+		} else {
+			err = reader.skipElement(element)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasVertex {
+		return nil, &ParseError{Reason: "ply must have vertex data"}
+	}
+
+	vertices := uint32(len(ply.vertices) / 3)
+
+	for _, index := range ply.indices {
+		if index >= vertices {
+			return nil, &ParseError{Reason: "Index " + strconv.FormatUint(uint64(index), 10) + " out of range"}
+		}
+	}
+
+	return ply, nil
+}
+
+// Reads the vertex element, mapping properties by name.
+func (p *Ply) readVertices(reader *plyReader, element plyElement) error {
+	slots := make([]int, len(element.properties))
+	found := [12]bool{}
+
+	for i, property := range element.properties {
+		slot, ok := plyVertexProperties[property.name]
+
+		if !ok || property.list {
+			slots[i] = -1
+			continue
+		}
+
+		slots[i] = slot
+		found[slot] = true
+	}
+
+	if !found[0] || !found[1] || !found[2] {
+		return reader.error("ply must have vertex data (x, y, z)")
+	}
+
+	p.hasNormal = found[3] || found[4] || found[5]
+	p.hasTexCoord = found[6] || found[7]
+	p.hasColor = found[8] || found[9] || found[10] || found[11]
+	prealloc := element.count
+
+	if prealloc > ply_max_prealloc {
+		prealloc = ply_max_prealloc
+	}
+
+	p.vertices = make([]float32, 0, prealloc*3)
+
+	for n := 0; n < element.count; n++ {
+		if err := reader.startElement(); err != nil {
+			return err
+		}
+
+		vertex := plyVertex{}
+		vertex[11] = 1 // opaque if alpha is missing
+
+		for i, property := range element.properties {
+			if property.list {
+				if _, err := reader.readList(property); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			value, err := reader.readValue(property.valueType)
+
+			if err != nil {
+				return err
+			}
+
+			if slots[i] >= 8 {
+				value = normalizeColor(value, property.valueType)
+			}
+
+			if slots[i] >= 0 {
+				vertex[slots[i]] = value
+			}
+		}
+
+		p.addVertex(vertex)
+	}
+
+	return nil
+}
+
+func (p *Ply) addVertex(vertex plyVertex) {
+	p.vertices = append(p.vertices, float32(vertex[0]), float32(vertex[1]), float32(vertex[2]))
+
+	if p.hasNormal {
+		p.normals = append(p.normals, float32(vertex[3]), float32(vertex[4]), float32(vertex[5]))
+	}
+
+	if p.hasTexCoord {
+		p.texCoords = append(p.texCoords, float32(vertex[6]), float32(vertex[7]))
+	}
+
+	if p.hasColor {
+		p.colors = append(p.colors, float32(vertex[8]), float32(vertex[9]), float32(vertex[10]), float32(vertex[11]))
+	}
+}
+
+// Converts integer colors to range 0-1, float colors are kept.
+func normalizeColor(value float64, valueType string) float64 {
+	switch valueType {
+	case "uchar", "uint8":
+		return value / math.MaxUint8
+	case "ushort", "uint16":
+		return value / math.MaxUint16
+	case "uint", "uint32":
+		return value / math.MaxUint32
+	}
+
+	return value
+}
+
+// Reads the face element and triangulates polygons as fans.
+func (p *Ply) readFaces(reader *plyReader, element plyElement) error {
+	hasIndices := false
+
+	for _, property := range element.properties {
+		if property.list && (property.name == "vertex_indices" || property.name == "vertex_index") {
+			hasIndices = true
+		}
+	}
+
+	if !hasIndices {
+		return reader.error("Face element has no vertex indices")
+	}
+
+	for n := 0; n < element.count; n++ {
+		if err := reader.startElement(); err != nil {
+			return err
+		}
+
+		for _, property := range element.properties {
+			if !property.list {
+				if _, err := reader.readValue(property.valueType); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			values, err := reader.readList(property)
+
+			if err != nil {
+				return err
+			}
+
+			if property.name != "vertex_indices" && property.name != "vertex_index" {
+				continue
+			}
+
+			if len(values) < 3 {
+				return reader.error("Face with less than 3 vertices")
+			}
+
+			indices := make([]uint32, len(values))
+
+			for i, value := range values {
+				if value < 0 || value > math.MaxUint32 || value != math.Trunc(value) {
+					return reader.error("Invalid index " + strconv.FormatFloat(value, 'g', -1, 64))
+				}
+
+				indices[i] = uint32(value)
+			}
+
+			for i := 1; i < len(indices)-1; i++ {
+				p.indices = append(p.indices, indices[0], indices[i], indices[i+1])
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reads the header and returns the elements in order.
+func (r *plyReader) readHeader() ([]plyElement, error) {
+	line, err := r.readLine()
+
+	if err != nil || strings.TrimSpace(line) != "ply" {
+		return nil, r.error("File is not of type ply")
+	}
+
+	hasFormat := false
+	elements := make([]plyElement, 0)
+
+	for {
+		line, err := r.readLine()
+
+		if err != nil {
+			return nil, r.error("Unexpected end of header")
+		}
+
+		fields := strings.Fields(strings.ToLower(line))
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "comment", "obj_info":
+		case "format":
+			if len(fields) != 3 {
+				return nil, r.error("Invalid format")
+			}
+
+			if err := r.setFormat(fields[1]); err != nil {
+				return nil, err
+			}
+
+			hasFormat = true
+		case "element":
+			if len(fields) != 3 {
+				return nil, r.error("Invalid element")
+			}
+
+			count, err := strconv.Atoi(fields[2])
+
+			if err != nil || count < 0 {
+				return nil, r.error("Number of " + fields[1] + " elements could not be parsed")
+			}
+
+			elements = append(elements, plyElement{fields[1], count, make([]plyProperty, 0)})
+		case "property":
+			if len(elements) == 0 {
+				return nil, r.error("Property without element")
+			}
+
+			property, err := r.parseProperty(fields)
+
+			if err != nil {
+				return nil, err
+			}
+
+			element := &elements[len(elements)-1]
+			element.properties = append(element.properties, property)
+		case "end_header":
+			if !hasFormat {
+				return nil, r.error("Missing format")
+			}
+
+			r.data = true
+
+			return elements, nil
+		default:
+			return nil, r.error("Unknown header keyword " + fields[0])
+		}
+	}
+}
+
+func (r *plyReader) setFormat(format string) error {
+	switch format {
+	case "ascii":
+		r.format = ply_format_ascii
+	case "binary_little_endian":
+		r.format = ply_format_binary_le
+		r.order = binary.LittleEndian
+	case "binary_big_endian":
+		r.format = ply_format_binary_be
+		r.order = binary.BigEndian
+	default:
+		return r.error("Unknown format " + format)
+	}
+
+	return nil
+}
+
+// Parses "property <type> <name>" or "property list <count type> <value type> <name>".
+func (r *plyReader) parseProperty(fields []string) (plyProperty, error) {
+	if len(fields) == 5 && fields[1] == "list" {
+		if _, ok := plyTypes[fields[2]]; !ok {
+			return plyProperty{}, r.error("Unknown type " + fields[2])
+		}
+
+		if _, ok := plyTypes[fields[3]]; !ok {
+			return plyProperty{}, r.error("Unknown type " + fields[3])
+		}
+
+		return plyProperty{fields[4], fields[3], fields[2], true}, nil
+	}
+
+	if len(fields) != 3 {
+		return plyProperty{}, r.error("Invalid property")
+	}
+
+	if _, ok := plyTypes[fields[1]]; !ok {
+		return plyProperty{}, r.error("Unknown type " + fields[1])
+	}
+
+	return plyProperty{fields[2], fields[1], "", false}, nil
+}
+
+// Reads a header or ascii data line without line break.
+func (r *plyReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	r.line++
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Starts reading the next element.
+// For ascii files, the next non empty line is read.
+func (r *plyReader) startElement() error {
+	if r.format != ply_format_ascii {
+		return nil
+	}
+
+	for {
+		line, err := r.readLine()
+
+		if err != nil {
+			return r.error("Unexpected end of file")
+		}
+
+		r.fields = strings.Fields(line)
+
+		if len(r.fields) > 0 {
+			return nil
+		}
+	}
+}
+
+// Reads the values of a list property.
+func (r *plyReader) readList(property plyProperty) ([]float64, error) {
+	count, err := r.readValue(property.countType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if count < 0 || count > ply_max_list || count != math.Trunc(count) {
+		return nil, r.error("Invalid list size " + strconv.FormatFloat(count, 'g', -1, 64))
+	}
+
+	values := make([]float64, int(count))
+
+	for i := range values {
+		if values[i], err = r.readValue(property.valueType); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Reads a single value of given type.
+func (r *plyReader) readValue(valueType string) (float64, error) {
+	if r.format == ply_format_ascii {
+		return r.readAsciiValue(valueType)
+	}
+
+	data := r.buffer[:plyTypes[valueType]]
+
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return 0, r.error("Unexpected end of file")
+	}
+
+	switch valueType {
+	case "char", "int8":
+		return float64(int8(data[0])), nil
+	case "uchar", "uint8":
+		return float64(data[0]), nil
+	case "short", "int16":
+		return float64(int16(r.order.Uint16(data))), nil
+	case "ushort", "uint16":
+		return float64(r.order.Uint16(data)), nil
+	case "int", "int32":
+		return float64(int32(r.order.Uint32(data))), nil
+	case "uint", "uint32":
+		return float64(r.order.Uint32(data)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(r.order.Uint32(data))), nil
+	}
+
+	return math.Float64frombits(r.order.Uint64(data)), nil
+}
+
+func (r *plyReader) readAsciiValue(valueType string) (float64, error) {
+	if len(r.fields) == 0 {
+		return 0, r.error("Expected more values")
+	}
+
+	field := r.fields[0]
+	r.fields = r.fields[1:]
+	var value float64
+	var err error
+
+	if valueType == "float" || valueType == "float32" || valueType == "double" || valueType == "float64" {
+		value, err = strconv.ParseFloat(field, 64)
+	} else {
+		var i int64
+		i, err = strconv.ParseInt(field, 10, 64)
+		value = float64(i)
+	}
+
+	if err != nil {
+		return 0, r.error("Invalid number " + field)
+	}
+
+	return value, nil
+}
+
+// Skips all values of an element.
+func (r *plyReader) skipElement(element plyElement) error {
+	for n := 0; n < element.count; n++ {
+		if err := r.startElement(); err != nil {
+			return err
+		}
+
+		for _, property := range element.properties {
+			var err error
+
+			if property.list {
+				_, err = r.readList(property)
+			} else {
+				_, err = r.readValue(property.valueType)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns a parse error at the current line.
+// The line is only set for ascii data and the header, as binary data has no lines.
+func (r *plyReader) error(reason string) error {
+	if r.data && r.format != ply_format_ascii {
+		return &ParseError{Reason: reason}
+	}
+
+	return &ParseError{Line: r.line, Reason: reason}
+}
@@ -0,0 +1,165 @@
+package goga
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const test_ply_header = "ply\nformat %v 1.0\n" +
+	"comment a quad\n" +
+	"element vertex 4\n" +
+	"property float x\nproperty float y\nproperty float z\n" +
+	"element face 1\n" +
+	"property list uchar int vertex_indices\n" +
+	"end_header\n"
+
+// Builds a binary ply containing a quad with given byte order.
+func buildBinaryPly(format string, order binary.ByteOrder) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(strings.Replace(test_ply_header, "%v", format, 1))
+	binary.Write(&buffer, order, []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0})
+	buffer.WriteByte(4)
+	binary.Write(&buffer, order, []int32{0, 1, 2, 3})
+	return buffer.Bytes()
+}
+
+func TestParsePlyBinary(t *testing.T) {
+	tests := []struct {
+		format string
+		order  binary.ByteOrder
+	}{
+		{"binary_little_endian", binary.LittleEndian},
+		{"binary_big_endian", binary.BigEndian},
+	}
+
+	for _, test := range tests {
+		ply, err := parsePly(bytes.NewReader(buildBinaryPly(test.format, test.order)))
+
+		if err != nil {
+			t.Fatalf("%v: %v", test.format, err)
+		}
+
+		if !equalFloat32(ply.vertices, []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0}) {
+			t.Fatalf("%v: unexpected vertices %v", test.format, ply.vertices)
+		}
+
+		if !equalUint32(ply.indices, []uint32{0, 1, 2, 0, 2, 3}) {
+			t.Fatalf("%v: unexpected indices %v", test.format, ply.indices)
+		}
+	}
+}
+
+func TestParsePlyAscii(t *testing.T) {
+	tests := []struct {
+		name      string
+		ply       string
+		vertices  []float32
+		texCoords []float32
+		normals   []float32
+		colors    []float32
+		indices   []uint32
+	}{
+		{"properties in any order",
+			"ply\nformat ascii 1.0\nelement vertex 3\nproperty float z\nproperty float nx\nproperty float x\nproperty float ny\nproperty float y\nproperty float nz\n" +
+				"element face 1\nproperty list uchar uint vertex_index\nend_header\n" +
+				"3 0 1 0 2 1\n6 0 4 0 5 1\n9 0 7 0 8 1\n3 0 1 2\n",
+			[]float32{1, 2, 3, 4, 5, 6, 7, 8, 9}, nil, []float32{0, 0, 1, 0, 0, 1, 0, 0, 1}, nil,
+			[]uint32{0, 1, 2}},
+		{"uchar colors",
+			"ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nproperty uchar red\nproperty uchar green\nproperty uchar blue\n" +
+				"element face 1\nproperty list uchar int vertex_indices\nend_header\n" +
+				"0 0 0 255 0 0\n1 0 0 0 255 0\n0 1 0 0 0 51\n3 0 1 2\n",
+			[]float32{0, 0, 0, 1, 0, 0, 0, 1, 0}, nil, nil,
+			[]float32{1, 0, 0, 1, 0, 1, 0, 1, 0, 0, 0.2, 1},
+			[]uint32{0, 1, 2}},
+		{"s/t texture coordinates",
+			"ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nproperty float s\nproperty float t\n" +
+				"element face 1\nproperty list uchar int vertex_indices\nend_header\n" +
+				"0 0 0 0 0\n1 0 0 1 0\n0 1 0 0 1\n3 0 1 2\n",
+			[]float32{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float32{0, 0, 1, 0, 0, 1}, nil, nil,
+			[]uint32{0, 1, 2}},
+		{"u/v texture coordinates",
+			"ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nproperty float v\nproperty float u\n" +
+				"element face 1\nproperty list uchar int vertex_indices\nend_header\n" +
+				"0 0 0 0.5 0\n1 0 0 0.5 1\n0 1 0 1 0\n3 0 1 2\n",
+			[]float32{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float32{0, 0.5, 1, 0.5, 0, 1}, nil, nil,
+			[]uint32{0, 1, 2}},
+		{"pentagon fan and skipped elements",
+			"ply\nformat ascii 1.0\nelement vertex 5\nproperty float x\nproperty float y\nproperty float z\n" +
+				"element face 2\nproperty uchar flags\nproperty list uchar int vertex_indices\n" +
+				"element edge 1\nproperty int vertex1\nproperty int vertex2\nend_header\n" +
+				"0 0 0\n1 0 0\n1 1 0\n0 1 0\n-1 1 0\n\n1 5 0 1 2 3 4\n0 3 4 3 2\n0 1\n",
+			[]float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0, -1, 1, 0}, nil, nil, nil,
+			[]uint32{0, 1, 2, 0, 2, 3, 0, 3, 4, 4, 3, 2}},
+	}
+
+	for _, test := range tests {
+		ply, err := parsePly(strings.NewReader(test.ply))
+
+		if err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+
+		if !equalFloat32(ply.vertices, test.vertices) || !equalUint32(ply.indices, test.indices) {
+			t.Fatalf("%v: unexpected vertices %v or indices %v", test.name, ply.vertices, ply.indices)
+		}
+
+		if ply.hasTexCoord != (test.texCoords != nil) || (test.texCoords != nil && !equalFloat32(ply.texCoords, test.texCoords)) {
+			t.Fatalf("%v: unexpected texture coordinates %v", test.name, ply.texCoords)
+		}
+
+		if ply.hasNormal != (test.normals != nil) || (test.normals != nil && !equalFloat32(ply.normals, test.normals)) {
+			t.Fatalf("%v: unexpected normals %v", test.name, ply.normals)
+		}
+
+		if ply.hasColor != (test.colors != nil) || (test.colors != nil && !equalFloat32(ply.colors, test.colors)) {
+			t.Fatalf("%v: unexpected colors %v", test.name, ply.colors)
+		}
+	}
+}
+
+func TestParsePlyErrors(t *testing.T) {
+	binaryPly := buildBinaryPly("binary_little_endian", binary.LittleEndian)
+	tests := []struct {
+		name string
+		ply  string
+		line int
+	}{
+		{"not a ply", "obj\n", 1},
+		{"empty", "", 0},
+		{"truncated header", "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\n", 4},
+		{"missing format", "ply\nelement vertex 0\nend_header\n", 3},
+		{"unknown format", "ply\nformat binary_middle_endian 1.0\n", 2},
+		{"unknown keyword", "ply\nformat ascii 1.0\nvertex 3\n", 3},
+		{"unknown type", "ply\nformat ascii 1.0\nelement vertex 1\nproperty vec3 x\n", 4},
+		{"property without element", "ply\nformat ascii 1.0\nproperty float x\n", 3},
+		{"invalid element count", "ply\nformat ascii 1.0\nelement vertex -1\n", 3},
+		{"missing position", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\n0\n", 5},
+		{"missing vertex element", "ply\nformat ascii 1.0\nelement face 0\nproperty list uchar int vertex_indices\nend_header\n", 0},
+		{"truncated ascii body", "ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n", 8},
+		{"missing ascii value", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0\n", 8},
+		{"invalid number", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 a 0\n", 8},
+		{"face with two vertices", "ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\n" +
+			"element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n2 0 1\n", 12},
+		{"index out of range", "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+			"element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 3\n", 0},
+		{"truncated binary body", string(binaryPly[:len(binaryPly)-3]), 0},
+		{"truncated binary list", string(binaryPly[:len(binaryPly)-16]), 0},
+	}
+
+	for _, test := range tests {
+		_, err := parsePly(strings.NewReader(test.ply))
+		var parseErr *ParseError
+
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%v: expected parse error, got %v", test.name, err)
+		}
+
+		if parseErr.Line != test.line {
+			t.Fatalf("%v: expected error at line %v, got %v", test.name, test.line, parseErr)
+		}
+	}
+}
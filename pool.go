@@ -0,0 +1,322 @@
+package goga
+
+import (
+	"errors"
+)
+
+// Statistics of an actor pool.
+// Hits count spawns reusing a pooled actor, misses count spawns creating a new one.
+type PoolStats struct {
+	Capacity, Free, Active int
+	Hits, Misses           int
+}
+
+// A pooled actor together with the components attached on spawn.
+type pooledActor struct {
+	actor      *Actor
+	components []interface{}
+	name       string
+	tags       []string
+}
+
+// An actor pool reuses actors and their components (including GL buffers),
+// for actors spawned and despawned frequently, like projectiles or particles.
+// Despawned actors are detached from all systems, but keep their components
+// until they are spawned again.
+type ActorPool struct {
+	engine *Engine
+	create func() (*Actor, []interface{}, error)
+	reset  func(*Actor, []interface{})
+	free   []pooledActor
+	active map[ActorId]pooledActor
+	hits   int
+	misses int
+}
+
+// Creates a new actor pool for the default engine.
+// Create is called to create new actors and their components, when the pool is empty.
+// Reset is called with the actor and its components when it is reused and can be nil.
+func NewActorPool(create func() (*Actor, []interface{}), reset func(*Actor, []interface{})) *ActorPool {
+	return defaultEngine.NewActorPool(create, reset)
+}
+
+// Creates a new actor pool.
+// See NewActorPool() for details.
+func (e *Engine) NewActorPool(create func() (*Actor, []interface{}), reset func(*Actor, []interface{})) *ActorPool {
+	return e.newActorPool(func() (*Actor, []interface{}, error) {
+		actor, components := create()
+		return actor, components, nil
+	}, reset)
+}
+
+func (e *Engine) newActorPool(create func() (*Actor, []interface{}, error), reset func(*Actor, []interface{})) *ActorPool {
+	pool := &ActorPool{}
+	pool.engine = e
+	pool.create = create
+	pool.reset = reset
+	pool.free = make([]pooledActor, 0)
+	pool.active = make(map[ActorId]pooledActor)
+
+	return pool
+}
+
+// Creates a new actor pool creating actors from prefab.
+// One actor is created immediately, so that an error is returned if the prefab does not exist or cannot be decoded.
+// If reset is nil, reused actors get the position (Pos2D, Pos3D) and animation (KeyframeAnimation)
+// values of the prefab restored. GL objects of decoded components are dropped when a pooled actor gets destroyed.
+func NewPrefabPool(name string, reset func(*Actor, []interface{})) (*ActorPool, error) {
+	return defaultEngine.NewPrefabPool(name, reset)
+}
+
+// Creates a new actor pool creating actors from prefab.
+// See NewPrefabPool() for details.
+func (e *Engine) NewPrefabPool(name string, reset func(*Actor, []interface{})) (*ActorPool, error) {
+	prefab := e.GetPrefab(name)
+
+	if prefab == nil {
+		return nil, errors.New("Prefab not found: " + name)
+	}
+
+	create := func() (*Actor, []interface{}, error) {
+		components, owned, err := e.decodePrefab(name, nil)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		list := make([]interface{}, 0, len(components))
+
+		for _, component := range components {
+			list = append(list, component)
+		}
+
+		actor := NewActor()
+		e.dropOnDestroy(actor, owned)
+
+		return actor, list, nil
+	}
+
+	pool := e.newActorPool(create, reset)
+	pool.createTags(prefab.Tags)
+
+	if err := pool.Prefill(1); err != nil {
+		return nil, err
+	}
+
+	if reset == nil {
+		pool.reset = newPrefabReset(pool.free[0].components)
+	}
+
+	return pool, nil
+}
+
+// Returns a reset function restoring position and animation components
+// to the values of given (freshly decoded) components.
+func newPrefabReset(components []interface{}) func(*Actor, []interface{}) {
+	var pos2D *Pos2D
+	var pos3D *Pos3D
+	var animation *KeyframeAnimation
+
+	for _, component := range components {
+		switch c := component.(type) {
+		case *Pos2D:
+			value := *c
+			pos2D = &value
+		case *Pos3D:
+			value := *c
+			pos3D = &value
+		case *KeyframeAnimation:
+			value := *c
+			animation = &value
+		}
+	}
+
+	return func(actor *Actor, components []interface{}) {
+		for _, component := range components {
+			switch c := component.(type) {
+			case *Pos2D:
+				if pos2D != nil {
+					c.Pos, c.Size, c.Scale, c.RotPoint = pos2D.Pos, pos2D.Size, pos2D.Scale, pos2D.RotPoint
+					c.Rot, c.Visible = pos2D.Rot, pos2D.Visible
+				}
+			case *Pos3D:
+				if pos3D != nil {
+					c.Pos, c.Size, c.Scale, c.RotPoint, c.Rot = pos3D.Pos, pos3D.Size, pos3D.Scale, pos3D.RotPoint, pos3D.Rot
+					c.Visible = pos3D.Visible
+				}
+			case *KeyframeAnimation:
+				if animation != nil {
+					*c = *animation
+				}
+			}
+		}
+	}
+}
+
+// Wraps create to add given tags to new actors.
+func (p *ActorPool) createTags(tags []string) {
+	create := p.create
+
+	p.create = func() (*Actor, []interface{}, error) {
+		actor, components, err := create()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, tag := range tags {
+			p.engine.AddActorTag(actor, tag)
+		}
+
+		return actor, components, nil
+	}
+}
+
+// Creates a new actor and removes it from the active actors when it gets destroyed (see DestroyActor()).
+func (p *ActorPool) newActor() (pooledActor, error) {
+	actor, components, err := p.create()
+
+	if err != nil {
+		return pooledActor{}, err
+	}
+
+	p.engine.AddDestroyCallback(actor, func(actor *Actor) {
+		delete(p.active, actor.GetId())
+		p.removeFree(actor)
+	})
+
+	return pooledActor{actor: actor, components: components}, nil
+}
+
+// Creates actors until the pool holds at least n free actors.
+// Returns an error if an actor cannot be created.
+func (p *ActorPool) Prefill(n int) error {
+	for len(p.free) < n {
+		entry, err := p.newActor()
+
+		if err != nil {
+			return err
+		}
+
+		p.free = append(p.free, p.despawned(entry.actor, entry.components))
+	}
+
+	return nil
+}
+
+// Spawns an actor from pool, or creates a new one if the pool is empty.
+// Its components are attached, so it is added to matching systems.
+// Returns an error if a new actor cannot be created.
+func (p *ActorPool) Spawn() (*Actor, error) {
+	var entry pooledActor
+
+	if len(p.free) > 0 {
+		entry = p.free[len(p.free)-1]
+		p.free[len(p.free)-1] = pooledActor{}
+		p.free = p.free[:len(p.free)-1]
+		p.hits++
+
+		if p.reset != nil {
+			p.reset(entry.actor, entry.components)
+		}
+
+		if entry.name != "" {
+			p.engine.SetActorName(entry.actor, entry.name)
+		}
+
+		for _, tag := range entry.tags {
+			p.engine.AddActorTag(entry.actor, tag)
+		}
+	} else {
+		var err error
+		entry, err = p.newActor()
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.misses++
+	}
+
+	p.engine.AttachComponents(entry.actor, entry.components...)
+	p.active[entry.actor.GetId()] = entry
+
+	return entry.actor, nil
+}
+
+// Spawns an actor from pool and sets its 2D position, if it has a Pos2D component.
+// Returns an error if a new actor cannot be created.
+func (p *ActorPool) SpawnAt(pos Vec2) (*Actor, error) {
+	actor, err := p.Spawn()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if pos2D := p.engine.components.GetPos2D(actor.GetId()); pos2D != nil {
+		pos2D.Pos = pos
+	}
+
+	return actor, nil
+}
+
+// Returns an actor to the pool.
+// It is removed from all systems and its components are detached, but kept for reuse.
+// Returns false if the actor was not spawned by this pool.
+// Use DespawnDeferred() while systems are updated.
+func (p *ActorPool) Despawn(actor *Actor) bool {
+	entry, ok := p.active[actor.GetId()]
+
+	if !ok {
+		return false
+	}
+
+	delete(p.active, actor.GetId())
+	p.engine.removeFromHierarchy(actor.GetId())
+	p.engine.DetachAllComponents(actor)
+	p.engine.RemoveActorById(actor.GetId())
+	p.free = append(p.free, p.despawned(entry.actor, entry.components))
+
+	return true
+}
+
+// Returns an actor to the pool at next sync point.
+// Use this instead of Despawn() while systems are updated, see DestroyDeferred() for details.
+// Actors not spawned by this pool (anymore) are ignored.
+func (p *ActorPool) DespawnDeferred(actor *Actor) {
+	p.engine.commands = append(p.engine.commands, command{actor, nil, false, p})
+}
+
+// Removes a destroyed actor from the free actors, so that it is not reused.
+func (p *ActorPool) removeFree(actor *Actor) {
+	for i, entry := range p.free {
+		if entry.actor == actor {
+			p.free = append(p.free[:i], p.free[i+1:]...)
+			return
+		}
+	}
+}
+
+// Removes name and tags of a despawned actor from lookup and remembers them for reuse.
+func (p *ActorPool) despawned(actor *Actor, components []interface{}) pooledActor {
+	entry := pooledActor{actor, components, actor.GetActorName(), actor.GetTags()}
+	p.engine.removeActorLookup(actor)
+
+	return entry
+}
+
+// Destroys all free actors, calling their destroy callbacks.
+// Active actors are not affected.
+func (p *ActorPool) Clear() {
+	free := p.free
+	p.free = make([]pooledActor, 0)
+
+	for _, entry := range free {
+		p.engine.DestroyActor(entry.actor)
+	}
+}
+
+// Returns the statistics of this pool.
+func (p *ActorPool) GetStats() PoolStats {
+	return PoolStats{len(p.free) + len(p.active), len(p.free), len(p.active), p.hits, p.misses}
+}
@@ -0,0 +1,153 @@
+package goga
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestPrefabPool(t *testing.T, e *Engine) *ActorPool {
+	pos := NewPos2D()
+	pos.Rot = 1
+	pos.Scale = Vec2{2, 2}
+	pos.Visible = true
+
+	if _, err := e.AddPrefab("bullet", pos, NewKeyframeAnimation(0, 3, true, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := e.NewPrefabPool("bullet", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pool
+}
+
+func TestPrefabPoolReset(t *testing.T) {
+	e := NewEngine()
+	pool := newTestPrefabPool(t, e)
+	actor, err := pool.Spawn()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := e.components.GetPos2D(actor.GetId())
+	animation := e.components.GetKeyframeAnimation(actor.GetId())
+	pos.Rot, pos.Scale, pos.Visible = 3, Vec2{5, 5}, false
+	animation.Current, animation.Interpolation = 2, 0.5
+	pool.Despawn(actor)
+
+	if reused, _ := pool.Spawn(); reused != actor {
+		t.Fatal("Expected actor to be reused")
+	}
+
+	if pos.Rot != 1 || pos.Scale.X != 2 || !pos.Visible {
+		t.Fatalf("Expected Pos2D to be reset to prefab values, got %v", *pos)
+	}
+
+	if animation.Current != 0 || animation.Interpolation != 0 {
+		t.Fatalf("Expected KeyframeAnimation to be reset to prefab values, got %v", *animation)
+	}
+}
+
+func TestPrefabPoolDestroyActor(t *testing.T) {
+	e := NewEngine()
+	pool := newTestPrefabPool(t, e)
+	actor, _ := pool.Spawn()
+	e.DestroyActor(actor)
+
+	if stats := pool.GetStats(); stats.Active != 0 {
+		t.Fatalf("Expected destroyed actor to be removed from pool, got %v active", stats.Active)
+	}
+
+	if pool.Despawn(actor) {
+		t.Fatal("Expected destroyed actor not to be despawned")
+	}
+}
+
+func TestPrefabPoolErrors(t *testing.T) {
+	e := NewEngine()
+
+	if _, err := e.NewPrefabPool("missing", nil); err == nil {
+		t.Fatal("Expected error for missing prefab")
+	}
+
+	if err := e.LoadPrefabs(strings.NewReader(`{"prefabs": {"broken": {"components": {"pos2d": 42}}}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.NewPrefabPool("broken", nil); err == nil {
+		t.Fatal("Expected decoding error for broken prefab")
+	}
+}
+
+func TestPrefabPoolDropsComponentsOnDestroy(t *testing.T) {
+	e := NewEngine()
+	codec := &testDropCodec{}
+	e.RegisterComponentCodec(codec)
+
+	if _, err := e.AddPrefab("bullet", NewPos2D(), &testDropComponent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := e.NewPrefabPool("bullet", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actor, _ := pool.Spawn()
+	pool.Despawn(actor)
+
+	if len(codec.decoded) != 1 || codec.decoded[0].dropped {
+		t.Fatal("Expected components of despawned actor to be kept for reuse")
+	}
+
+	pool.Clear()
+
+	if !codec.decoded[0].dropped {
+		t.Fatal("Expected components to be dropped when the pooled actor is destroyed")
+	}
+}
+
+func TestPrefabPoolDestroyFreeActor(t *testing.T) {
+	e := NewEngine()
+	pool := newTestPrefabPool(t, e)
+	actor, _ := pool.Spawn()
+	pool.Despawn(actor)
+	e.DestroyActor(actor)
+
+	if stats := pool.GetStats(); stats.Free != 0 || stats.Capacity != 0 {
+		t.Fatalf("Expected destroyed actor to be removed from free list, got %v", stats)
+	}
+
+	if spawned, _ := pool.Spawn(); spawned == actor {
+		t.Fatal("Expected destroyed actor not to be reused")
+	}
+}
+
+func TestPrefabPoolDespawnDeferred(t *testing.T) {
+	e := NewEngine()
+	pool := newTestPrefabPool(t, e)
+	actor, _ := pool.Spawn()
+	pool.DespawnDeferred(actor)
+
+	if stats := pool.GetStats(); stats.Active != 1 || e.components.GetPos2D(actor.GetId()) == nil {
+		t.Fatal("Expected actor to be active until next sync point")
+	}
+
+	e.sync()
+
+	if stats := pool.GetStats(); stats.Active != 0 || stats.Free != 1 || e.components.GetPos2D(actor.GetId()) != nil {
+		t.Fatalf("Expected actor to be despawned on sync, got %v", stats)
+	}
+
+	pool.DespawnDeferred(actor)
+	e.sync()
+
+	if stats := pool.GetStats(); stats.Free != 1 {
+		t.Fatalf("Expected actor despawned twice to be ignored, got %v", stats)
+	}
+}
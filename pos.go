@@ -1,11 +1,44 @@
 package goga
 
 // Position component for 2D objects.
+// A Pos2D can have a parent, the world matrix is then relative to the parent's transformation.
+// The parent's size is not inherited.
 type Pos2D struct {
 	Pos, Size, Scale, RotPoint Vec2
 	Rot                        float64
 	Visible                    bool
 	M                          Mat3
+
+	parent    *Pos2D
+	transform Mat3
+	last      pos2DState
+	cache     transformCache
+}
+
+// Values the cached transformation of a Pos2D was calculated from.
+type pos2DState struct {
+	Pos, Scale, RotPoint Vec2
+	Rot                  float64
+}
+
+// Change detection for cached world transformations.
+// The version is increased each time the transformation changes,
+// so children can detect their parent has changed.
+type transformCache struct {
+	valid                  bool
+	version, parentVersion uint64
+}
+
+// Returns true if the cached transformation is still valid for given parent version.
+func (c *transformCache) isValid(parentVersion uint64) bool {
+	return c.valid && c.parentVersion == parentVersion
+}
+
+// Marks the cached transformation as recalculated.
+func (c *transformCache) update(parentVersion uint64) {
+	c.valid = true
+	c.version++
+	c.parentVersion = parentVersion
 }
 
 // Creates a default initialized Pos2D.
@@ -27,6 +60,84 @@ func (p *Pos2D) CalcModel() *Mat3 {
 	return &p.M
 }
 
+// Sets the parent of this position, or removes it if nil.
+// The position keeps its local values, so it will move with the parent.
+// Use SetParent() on engine to keep the world transformation and link actors.
+func (p *Pos2D) SetParent(parent *Pos2D) {
+	p.parent = parent
+	p.cache.valid = false
+}
+
+// Returns the parent position or nil.
+func (p *Pos2D) GetParent() *Pos2D {
+	return p.parent
+}
+
+// Calculates the world transformation, which is the parent's transformation times the local one.
+// The size is not included. The result is cached until a value of this or a parent position changes.
+func (p *Pos2D) calcTransform() *Mat3 {
+	var parentVersion uint64
+
+	if p.parent != nil {
+		p.parent.calcTransform()
+		parentVersion = p.parent.cache.version
+	}
+
+	state := pos2DState{p.Pos, p.Scale, p.RotPoint, p.Rot}
+
+	if p.cache.isValid(parentVersion) && state == p.last {
+		return &p.transform
+	}
+
+	p.transform.Identity()
+	p.transform.Translate(Vec2{p.Pos.X + p.RotPoint.X, p.Pos.Y + p.RotPoint.Y})
+	p.transform.Rotate(p.Rot)
+	p.transform.Translate(Vec2{-p.RotPoint.X, -p.RotPoint.Y})
+	p.transform.Scale(p.Scale)
+
+	if p.parent != nil {
+		p.transform = *MultMat3(&p.parent.transform, &p.transform)
+	}
+
+	p.last = state
+	p.cache.update(parentVersion)
+
+	return &p.transform
+}
+
+// Calculates model matrix for 2D positioning in world space.
+// Without parent, this is equal to CalcModel().
+func (p *Pos2D) CalcWorld() *Mat3 {
+	p.M = *p.calcTransform()
+	p.M.Scale(p.Size)
+
+	return &p.M
+}
+
+// Returns the world position of the origin of object.
+func (p *Pos2D) GetWorldPos() Vec2 {
+	m := p.calcTransform()
+	return Vec2{m.Values[6], m.Values[7]}
+}
+
+// Sets the local values, so that the world transformation (without size) equals given one.
+// Shearing caused by non uniform scaling of rotated parents is lost.
+func (p *Pos2D) setWorldTransform(pos Vec2, rot float64, scale Vec2) {
+	if p.parent != nil {
+		parentPos, parentRot, parentScale := decomposeMat3(p.parent.calcTransform())
+		pos = rotateVec2(Vec2{pos.X - parentPos.X, pos.Y - parentPos.Y}, -parentRot)
+		pos = Vec2{pos.X / parentScale.X, pos.Y / parentScale.Y}
+		rot -= parentRot
+		scale = Vec2{scale.X / parentScale.X, scale.Y / parentScale.Y}
+	}
+
+	// the rotation point moves the translation
+	rotPoint := rotateVec2(p.RotPoint, rot)
+	p.Pos = Vec2{pos.X - p.RotPoint.X + rotPoint.X, pos.Y - p.RotPoint.Y + rotPoint.Y}
+	p.Rot = rot
+	p.Scale = scale
+}
+
 // Returns the center of object.
 // Assumes y = 0 is bottom left corner, if not you have to subtract height of object.
 func (p *Pos2D) GetCenter() Vec2 {
@@ -39,10 +150,22 @@ func (p *Pos2D) PointInRect(point Vec2) bool {
 }
 
 // Position component for 3D objects
+// A Pos3D can have a parent, the world matrix is then relative to the parent's transformation.
+// The parent's size is not inherited.
 type Pos3D struct {
 	Pos, Size, Scale, RotPoint, Rot Vec3
 	Visible                         bool
 	M                               Mat4
+
+	parent    *Pos3D
+	transform Mat4
+	last      pos3DState
+	cache     transformCache
+}
+
+// Values the cached transformation of a Pos3D was calculated from.
+type pos3DState struct {
+	Pos, Scale, RotPoint, Rot Vec3
 }
 
 // Creates a default initialized Pos3D.
@@ -66,6 +189,87 @@ func (p *Pos3D) CalcModel() *Mat4 {
 	return &p.M
 }
 
+// Sets the parent of this position, or removes it if nil.
+// The position keeps its local values, so it will move with the parent.
+// Use SetParent() on engine to keep the world transformation and link actors.
+func (p *Pos3D) SetParent(parent *Pos3D) {
+	p.parent = parent
+	p.cache.valid = false
+}
+
+// Returns the parent position or nil.
+func (p *Pos3D) GetParent() *Pos3D {
+	return p.parent
+}
+
+// Calculates the world transformation, which is the parent's transformation times the local one.
+// The size is not included. The result is cached until a value of this or a parent position changes.
+func (p *Pos3D) calcTransform() *Mat4 {
+	var parentVersion uint64
+
+	if p.parent != nil {
+		p.parent.calcTransform()
+		parentVersion = p.parent.cache.version
+	}
+
+	state := pos3DState{p.Pos, p.Scale, p.RotPoint, p.Rot}
+
+	if p.cache.isValid(parentVersion) && state == p.last {
+		return &p.transform
+	}
+
+	p.transform.Identity()
+	p.transform.Translate(Vec3{p.Pos.X + p.RotPoint.X, p.Pos.Y + p.RotPoint.Y, p.Pos.Z + p.RotPoint.Z})
+	p.transform.Rotate(p.Rot.X, Vec3{1, 0, 0})
+	p.transform.Rotate(p.Rot.Y, Vec3{0, 1, 0})
+	p.transform.Rotate(p.Rot.Z, Vec3{0, 0, 1})
+	p.transform.Translate(Vec3{-p.RotPoint.X, -p.RotPoint.Y, -p.RotPoint.Z})
+	p.transform.Scale(p.Scale)
+
+	if p.parent != nil {
+		p.transform = *MultMat4(&p.parent.transform, &p.transform)
+	}
+
+	p.last = state
+	p.cache.update(parentVersion)
+
+	return &p.transform
+}
+
+// Calculates model matrix for 3D positioning in world space.
+// Without parent, this is equal to CalcModel().
+func (p *Pos3D) CalcWorld() *Mat4 {
+	p.M = *p.calcTransform()
+	p.M.Scale(p.Size)
+
+	return &p.M
+}
+
+// Returns the world position of the origin of object.
+func (p *Pos3D) GetWorldPos() Vec3 {
+	m := p.calcTransform()
+	return Vec3{m.Values[12], m.Values[13], m.Values[14]}
+}
+
+// Sets the local values, so that the world transformation (without size) equals given one.
+// The rotation is given as matrix with normalized axes.
+// Shearing caused by non uniform scaling of rotated parents is lost.
+func (p *Pos3D) setWorldTransform(pos Vec3, rot [9]float64, scale Vec3) {
+	if p.parent != nil {
+		parentPos, parentRot, parentScale := decomposeMat4(p.parent.calcTransform())
+		rot = multRot(transposeRot(parentRot), rot)
+		pos = multRotVec(transposeRot(parentRot), Vec3{pos.X - parentPos.X, pos.Y - parentPos.Y, pos.Z - parentPos.Z})
+		pos = Vec3{pos.X / parentScale.X, pos.Y / parentScale.Y, pos.Z / parentScale.Z}
+		scale = Vec3{scale.X / parentScale.X, scale.Y / parentScale.Y, scale.Z / parentScale.Z}
+	}
+
+	// the rotation point moves the translation
+	rotPoint := multRotVec(rot, p.RotPoint)
+	p.Pos = Vec3{pos.X - p.RotPoint.X + rotPoint.X, pos.Y - p.RotPoint.Y + rotPoint.Y, pos.Z - p.RotPoint.Z + rotPoint.Z}
+	p.Rot = eulerFromRot(rot)
+	p.Scale = scale
+}
+
 // Returns the center of object.
 // Assumes y = 0 is bottom left corner, if not you have to subtract height of object.
 func (p *Pos3D) GetCenter() Vec3 {
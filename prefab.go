@@ -0,0 +1,318 @@
+package goga
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+// A component codec implementing this interface can mark its components as shared.
+// Shared components are decoded once per prefab and used by all instances,
+// instead of creating a copy for each instance. Use this for immutable components
+// holding GL objects, like keyframe sets.
+type SharedComponentCodec interface {
+	ComponentCodec
+	IsShared() bool
+}
+
+// A prefab is a named template for actors.
+// It stores component values encoded by their codecs, so each instance gets its own copy.
+// Systems are joined automatically through the attached components.
+type Prefab struct {
+	Name       string
+	Tags       []string
+	Components map[string]json.RawMessage
+
+	shared map[string]interface{}
+}
+
+// Prefab file format:
+//
+//	{
+//	    "prefabs": {
+//	        "enemy": {
+//	            "tags": ["enemy"],
+//	            "components": {
+//	                "pos2d": {"size": {"X": 32, "Y": 32}, ...},
+//	                "tex": "enemy.png"
+//	            }
+//	        },
+//	        ...
+//	    }
+//	}
+type jsonPrefabs struct {
+	Prefabs map[string]jsonPrefab `json:"prefabs"`
+}
+
+type jsonPrefab struct {
+	Tags       []string                   `json:"tags,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// Creates a prefab from given components and adds it.
+// The components are encoded immediately, so changing them afterwards does not change the prefab.
+// An existing prefab with the same name is replaced.
+func AddPrefab(name string, components ...interface{}) (*Prefab, error) {
+	return defaultEngine.AddPrefab(name, components...)
+}
+
+// Creates a prefab from given components and adds it.
+// See AddPrefab() for details.
+func (e *Engine) AddPrefab(name string, components ...interface{}) (*Prefab, error) {
+	prefab := &Prefab{Name: name, Tags: make([]string, 0)}
+	prefab.Components = make(map[string]json.RawMessage)
+
+	for _, component := range components {
+		codec := e.GetComponentCodecByType(ComponentTypeOf(component))
+
+		if codec == nil {
+			return nil, errors.New("No codec registered for component in prefab " + name)
+		}
+
+		data, err := codec.Encode(e, component)
+
+		if err != nil {
+			return nil, errors.New("Prefab " + name + ", " + codec.GetName() + ": " + err.Error())
+		}
+
+		prefab.Components[codec.GetName()] = data
+	}
+
+	e.prefabs[name] = prefab
+
+	return prefab, nil
+}
+
+// Loads prefabs from JSON and adds them.
+// Existing prefabs with the same name are replaced.
+func LoadPrefabs(r io.Reader) error {
+	return defaultEngine.LoadPrefabs(r)
+}
+
+// Loads prefabs from JSON and adds them.
+// See LoadPrefabs() for details.
+func (e *Engine) LoadPrefabs(r io.Reader) error {
+	prefabs := jsonPrefabs{}
+
+	if err := json.NewDecoder(r).Decode(&prefabs); err != nil {
+		return err
+	}
+
+	for name, value := range prefabs.Prefabs {
+		for component := range value.Components {
+			if e.GetComponentCodecByName(component) == nil {
+				return errors.New("No codec registered for component " + component + " in prefab " + name)
+			}
+		}
+	}
+
+	for name, value := range prefabs.Prefabs {
+		e.prefabs[name] = &Prefab{Name: name, Tags: value.Tags, Components: value.Components}
+	}
+
+	return nil
+}
+
+// Loads prefabs from JSON file in the virtual file system and adds them.
+func LoadPrefabsFile(path string) error {
+	return defaultEngine.LoadPrefabsFile(path)
+}
+
+// Loads prefabs from JSON file in the virtual file system and adds them.
+func (e *Engine) LoadPrefabsFile(path string) error {
+	file, err := e.OpenFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return e.LoadPrefabs(file)
+}
+
+// Returns the prefab for given name or nil if not found.
+func GetPrefab(name string) *Prefab {
+	return defaultEngine.GetPrefab(name)
+}
+
+// Returns the prefab for given name or nil if not found.
+func (e *Engine) GetPrefab(name string) *Prefab {
+	return e.prefabs[name]
+}
+
+// Returns the names of all prefabs in alphabetical order.
+func GetPrefabNames() []string {
+	return defaultEngine.GetPrefabNames()
+}
+
+// Returns the names of all prefabs in alphabetical order.
+func (e *Engine) GetPrefabNames() []string {
+	names := make([]string, 0, len(e.prefabs))
+
+	for name := range e.prefabs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Removes a prefab by name.
+// Returns false if it could not be found.
+func RemovePrefab(name string) bool {
+	return defaultEngine.RemovePrefab(name)
+}
+
+// Removes a prefab by name.
+// Returns false if it could not be found.
+func (e *Engine) RemovePrefab(name string) bool {
+	if _, ok := e.prefabs[name]; !ok {
+		return false
+	}
+
+	delete(e.prefabs, name)
+
+	return true
+}
+
+// Creates a new actor from prefab at given 2D position.
+// The prefab must contain a Pos2D component.
+// Overrides are components replacing the ones of the prefab with the same type,
+// or adding components not part of the prefab.
+// The components are attached, so the actor is added to matching systems.
+// GL objects of decoded components are dropped when the actor gets destroyed,
+// shared components and overrides are kept.
+func Instantiate(name string, pos Vec2, overrides ...interface{}) (*Actor, error) {
+	return defaultEngine.Instantiate(name, pos, overrides...)
+}
+
+// Creates a new actor from prefab at given 2D position.
+// See Instantiate() for details.
+func (e *Engine) Instantiate(name string, pos Vec2, overrides ...interface{}) (*Actor, error) {
+	components, owned, err := e.decodePrefab(name, overrides)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pos2D, ok := components[Pos2DType].(*Pos2D)
+
+	if !ok {
+		dropComponents(owned)
+		return nil, errors.New("Prefab " + name + " has no Pos2D component")
+	}
+
+	pos2D.Pos = pos
+
+	return e.spawnPrefab(name, components, owned), nil
+}
+
+// Creates a new actor from prefab at given 3D position.
+// The prefab must contain a Pos3D component.
+// See Instantiate() for details.
+func Instantiate3D(name string, pos Vec3, overrides ...interface{}) (*Actor, error) {
+	return defaultEngine.Instantiate3D(name, pos, overrides...)
+}
+
+// Creates a new actor from prefab at given 3D position.
+// See Instantiate3D() for details.
+func (e *Engine) Instantiate3D(name string, pos Vec3, overrides ...interface{}) (*Actor, error) {
+	components, owned, err := e.decodePrefab(name, overrides)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pos3D, ok := components[Pos3DType].(*Pos3D)
+
+	if !ok {
+		dropComponents(owned)
+		return nil, errors.New("Prefab " + name + " has no Pos3D component")
+	}
+
+	pos3D.Pos = pos
+
+	return e.spawnPrefab(name, components, owned), nil
+}
+
+// Decodes the components of a prefab and applies overrides.
+// Returns the components by type and the ones owned by the instance,
+// which are the decoded components not shared by the prefab.
+// Components replaced by overrides are not decoded.
+func (e *Engine) decodePrefab(name string, overrides []interface{}) (map[ComponentType]interface{}, []interface{}, error) {
+	prefab, ok := e.prefabs[name]
+
+	if !ok {
+		return nil, nil, errors.New("Prefab not found: " + name)
+	}
+
+	if prefab.shared == nil {
+		prefab.shared = make(map[string]interface{})
+	}
+
+	components := make(map[ComponentType]interface{})
+	owned := make([]interface{}, 0, len(prefab.Components))
+
+	for _, component := range overrides {
+		components[ComponentTypeOf(component)] = component
+	}
+
+	for codecName, data := range prefab.Components {
+		codec := e.GetComponentCodecByName(codecName)
+
+		if codec == nil {
+			dropComponents(owned)
+			return nil, nil, errors.New("No codec registered for component " + codecName + " in prefab " + name)
+		}
+
+		if _, ok := components[codec.GetType()]; ok {
+			continue
+		}
+
+		shared, isShared := codec.(SharedComponentCodec)
+		isShared = isShared && shared.IsShared()
+
+		if component, ok := prefab.shared[codecName]; ok && isShared {
+			components[codec.GetType()] = component
+			continue
+		}
+
+		component, err := codec.Decode(e, data)
+
+		if err != nil {
+			dropComponents(owned)
+			return nil, nil, errors.New("Prefab " + name + ", " + codecName + ": " + err.Error())
+		}
+
+		if isShared {
+			prefab.shared[codecName] = component
+		} else {
+			owned = append(owned, component)
+		}
+
+		components[codec.GetType()] = component
+	}
+
+	return components, owned, nil
+}
+
+func (e *Engine) spawnPrefab(name string, components map[ComponentType]interface{}, owned []interface{}) *Actor {
+	actor := NewActor()
+	list := make([]interface{}, 0, len(components))
+
+	for _, component := range components {
+		list = append(list, component)
+	}
+
+	e.AttachComponents(actor, list...)
+	e.dropOnDestroy(actor, owned)
+
+	for _, tag := range e.prefabs[name].Tags {
+		e.AddActorTag(actor, tag)
+	}
+
+	return actor
+}
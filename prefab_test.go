@@ -0,0 +1,223 @@
+package goga
+
+import (
+	"strings"
+	"testing"
+)
+
+const test_prefabs = `{
+	"prefabs": {
+		"enemy": {
+			"tags": ["enemy", "hostile"],
+			"components": {
+				"pos2d": {"size": {"X": 32, "Y": 16}, "scale": {"X": 1, "Y": 1}, "visible": true}
+			}
+		},
+		"cube": {
+			"components": {
+				"pos3d": {"size": {"X": 1, "Y": 1, "Z": 1}, "scale": {"X": 2, "Y": 2, "Z": 2}, "visible": true}
+			}
+		}
+	}
+}`
+
+func TestAddPrefab(t *testing.T) {
+	e := NewEngine()
+	pos := NewPos2D()
+	pos.Size = Vec2{8, 8}
+	prefab, err := e.AddPrefab("bullet", pos)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos.Size = Vec2{1, 1}
+
+	if e.GetPrefab("bullet") != prefab || len(prefab.Components) != 1 {
+		t.Fatal("Expected prefab to be added")
+	}
+
+	actor, err := e.Instantiate("bullet", Vec2{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := e.components.GetPos2D(actor.GetId()).Size; size != (Vec2{8, 8}) {
+		t.Fatalf("Expected components to be encoded when added, got size %v", size)
+	}
+
+	if replaced, _ := e.AddPrefab("bullet", NewPos2D()); e.GetPrefab("bullet") != replaced {
+		t.Fatal("Expected prefab to be replaced")
+	}
+
+	if _, err := e.AddPrefab("invalid", &struct{}{}); err == nil || e.GetPrefab("invalid") != nil {
+		t.Fatal("Expected error for component without codec")
+	}
+}
+
+func TestLoadPrefabs(t *testing.T) {
+	e := NewEngine()
+
+	if err := e.LoadPrefabs(strings.NewReader(test_prefabs)); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := e.GetPrefabNames(); !equalStrings(names, []string{"cube", "enemy"}) {
+		t.Fatalf("Expected prefabs in alphabetical order, got %v", names)
+	}
+
+	if enemy := e.GetPrefab("enemy"); !equalStrings(enemy.Tags, []string{"enemy", "hostile"}) {
+		t.Fatalf("Expected prefab tags, got %v", enemy.Tags)
+	}
+
+	invalid := `{"prefabs": {"a": {"components": {"pos2d": {}}}, "b": {"components": {"unknown": {}}}}}`
+
+	if err := e.LoadPrefabs(strings.NewReader(invalid)); err == nil || e.GetPrefab("a") != nil {
+		t.Fatal("Expected error for unknown codec and no prefab to be added")
+	}
+
+	if !e.RemovePrefab("cube") || e.RemovePrefab("cube") || e.GetPrefab("cube") != nil {
+		t.Fatal("Expected prefab to be removed once")
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	e := NewEngine()
+
+	if err := e.LoadPrefabs(strings.NewReader(test_prefabs)); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := e.Instantiate("enemy", Vec2{10, 20})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _ := e.Instantiate("enemy", Vec2{30, 40})
+	firstPos := e.components.GetPos2D(first.GetId())
+	secondPos := e.components.GetPos2D(second.GetId())
+
+	if firstPos == secondPos || firstPos.Pos != (Vec2{10, 20}) || secondPos.Pos != (Vec2{30, 40}) || firstPos.Size != (Vec2{32, 16}) {
+		t.Fatalf("Expected each instance to get its own copy at given position, got %v and %v", *firstPos, *secondPos)
+	}
+
+	if tagged := e.FindActorsByTag("hostile"); len(tagged) != 2 {
+		t.Fatalf("Expected instances to be tagged, got %v", tagged)
+	}
+
+	override := NewPos2D()
+	override.Size = Vec2{64, 64}
+	third, _ := e.Instantiate("enemy", Vec2{1, 1}, override, NewPos3D())
+
+	if pos := e.components.GetPos2D(third.GetId()); pos != override || pos.Pos != (Vec2{1, 1}) || e.components.GetPos3D(third.GetId()) == nil {
+		t.Fatal("Expected overrides to replace and add components")
+	}
+
+	cube, err := e.Instantiate3D("cube", Vec3{1, 2, 3})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pos := e.components.GetPos3D(cube.GetId()); pos.Pos != (Vec3{1, 2, 3}) || pos.Scale != (Vec3{2, 2, 2}) {
+		t.Fatalf("Expected 3D instance at given position, got %v", *pos)
+	}
+
+	if _, err := e.Instantiate("cube", Vec2{}); err == nil {
+		t.Fatal("Expected error for prefab without Pos2D")
+	}
+
+	if _, err := e.Instantiate3D("enemy", Vec3{}); err == nil {
+		t.Fatal("Expected error for prefab without Pos3D")
+	}
+
+	if _, err := e.Instantiate("missing", Vec2{}); err == nil {
+		t.Fatal("Expected error for missing prefab")
+	}
+}
+
+func TestInstantiateSharedComponents(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		e := NewEngine()
+		codec := &testDropCodec{shared: shared}
+		e.RegisterComponentCodec(codec)
+
+		if _, err := e.AddPrefab("enemy", NewPos2D(), &testDropComponent{}); err != nil {
+			t.Fatal(err)
+		}
+
+		first, _ := e.Instantiate("enemy", Vec2{})
+		second, _ := e.Instantiate("enemy", Vec2{})
+		firstComponent := e.GetComponent(first.GetId(), codec.GetType())
+		secondComponent := e.GetComponent(second.GetId(), codec.GetType())
+
+		if (firstComponent == secondComponent) != shared {
+			t.Fatalf("shared %v: expected components to be shared only by shared codecs", shared)
+		}
+
+		if expected := map[bool]int{false: 2, true: 1}[shared]; len(codec.decoded) != expected {
+			t.Fatalf("shared %v: expected %v decoded components, got %v", shared, expected, len(codec.decoded))
+		}
+	}
+}
+
+func TestInstantiateDropsComponentsOnDestroy(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		e := NewEngine()
+		codec := &testDropCodec{shared: shared}
+		e.RegisterComponentCodec(codec)
+
+		if _, err := e.AddPrefab("enemy", NewPos2D(), &testDropComponent{}); err != nil {
+			t.Fatal(err)
+		}
+
+		first, _ := e.Instantiate("enemy", Vec2{})
+		second, err := e.Instantiate("enemy", Vec2{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e.DestroyActor(first)
+		e.DestroyActor(second)
+
+		for _, component := range codec.decoded {
+			if component.dropped == shared {
+				t.Fatalf("shared %v: expected instance components to be dropped and shared ones to be kept", shared)
+			}
+		}
+	}
+}
+
+func TestInstantiateKeepsOverrides(t *testing.T) {
+	e := NewEngine()
+	codec := &testDropCodec{}
+	e.RegisterComponentCodec(codec)
+
+	if _, err := e.AddPrefab("enemy", NewPos2D(), &testDropComponent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	override := &testDropComponent{}
+	actor, err := e.Instantiate("enemy", Vec2{}, override)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codec.decoded) != 0 {
+		t.Fatal("Expected overridden component not to be decoded")
+	}
+
+	e.DestroyActor(actor)
+
+	if override.dropped {
+		t.Fatal("Expected override to be kept")
+	}
+
+	if _, err := e.Instantiate3D("enemy", Vec3{}); err == nil || len(codec.decoded) != 1 || !codec.decoded[0].dropped {
+		t.Fatal("Expected decoded components to be dropped if the prefab cannot be instantiated")
+	}
+}
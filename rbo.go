@@ -0,0 +1,44 @@
+package goga
+
+import (
+	"github.com/go-gl/gl/v3.2-core/gl"
+)
+
+// Render Buffer Object.
+type RBO struct {
+	id uint32
+}
+
+// Creates a new RBO.
+// Attach it to an FBO to render to, when the result doesn't need to be read as texture.
+func NewRBO() *RBO {
+	rbo := &RBO{}
+	gl.GenRenderbuffers(1, &rbo.id)
+
+	return rbo
+}
+
+// Drops this RBO.
+func (r *RBO) Drop() {
+	gl.DeleteRenderbuffers(1, &r.id)
+}
+
+// Binds RBO.
+func (r *RBO) Bind() {
+	gl.BindRenderbuffer(gl.RENDERBUFFER, r.id)
+}
+
+// Unbinds.
+func (r *RBO) Unbind() {
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+}
+
+// Allocates the storage of given format and size.
+func (r *RBO) Storage(format uint32, width, height int32) {
+	gl.RenderbufferStorage(gl.RENDERBUFFER, format, width, height)
+}
+
+// Returns the GL ID.
+func (r *RBO) GetId() uint32 {
+	return r.id
+}
@@ -1,8 +1,9 @@
 package goga
 
 import (
-	"errors"
-	"io/ioutil"
+	"encoding/json"
+	"io"
+	"io/fs"
 	"log"
 	"path/filepath"
 	"strings"
@@ -23,29 +24,70 @@ type Res interface {
 // Resource loader interface.
 // The loader accepts files by file extension.
 // and loads them if accepted.
+// Load is passed the file opened from the virtual file system as *ResSource, see MountFS().
 type ResLoader interface {
-	Load(string) (Res, error)
+	Load(io.Reader) (Res, error)
 	Ext() string
 }
 
-var (
-	resloader []ResLoader
-	resources []Res
-)
+// A resource loader implementing this interface accepts files of multiple extensions.
+// Exts returns all of them, Ext returns the main one, which is used in log messages.
+type MultiExtResLoader interface {
+	ResLoader
+	Exts() []string
+}
+
+// File of a resource passed to loaders, together with the path it was opened by.
+// Loaders can use the path to read files referenced relative to it, like glTF buffers, using ReadFile().
+type ResSource struct {
+	fs.File
+	Path string
+
+	mounts []mount
+}
+
+// Creates the source of a resource file, opened from the virtual file system of the engine.
+func (e *Engine) newResSource(file fs.File, path string) *ResSource {
+	mounts := make([]mount, len(e.mounts))
+	copy(mounts, e.mounts)
+
+	return &ResSource{file, path, mounts}
+}
+
+// Reads a file from the virtual file system of the engine the resource was opened from.
+// The file systems mounted when the resource was opened are used,
+// so this can be called while decoding asynchronously (see AsyncResLoader).
+func (s *ResSource) ReadFile(name string) ([]byte, error) {
+	file, err := openFile(s.mounts, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
 
 // Adds a loader.
-// If a loader with the same file extension exists already, false will be returned.
+// If a loader for one of the file extensions exists already, false will be returned.
 func AddLoader(loader ResLoader) bool {
-	ext := strings.ToLower(loader.Ext())
+	return defaultEngine.AddLoader(loader)
+}
+
+// Adds a loader.
+// If a loader for one of the file extensions exists already, false will be returned.
+func (e *Engine) AddLoader(loader ResLoader) bool {
+	exts := loaderExts(loader)
 
-	for _, l := range resloader {
-		if strings.ToLower(l.Ext()) == ext {
+	for _, ext := range exts {
+		if e.GetLoaderByExt(ext) != nil {
 			return false
 		}
 	}
 
-	resloader = append(resloader, loader)
-	log.Print("Added loader for " + loader.Ext() + " files")
+	e.resloader = append(e.resloader, loader)
+	log.Print("Added loader for " + strings.Join(exts, ", ") + " files")
 
 	return true
 }
@@ -53,10 +95,16 @@ func AddLoader(loader ResLoader) bool {
 // Removes a loader.
 // Returns false if loader could not be found.
 func RemoveLoader(loader ResLoader) bool {
-	for i, l := range resloader {
+	return defaultEngine.RemoveLoader(loader)
+}
+
+// Removes a loader.
+// Returns false if loader could not be found.
+func (e *Engine) RemoveLoader(loader ResLoader) bool {
+	for i, l := range e.resloader {
 		if l == loader {
-			resloader = append(resloader[:i], resloader[i+1:]...)
-			log.Print("Removed loader for " + loader.Ext() + " files")
+			e.resloader = append(e.resloader[:i], e.resloader[i+1:]...)
+			log.Print("Removed loader for " + strings.Join(loaderExts(loader), ", ") + " files")
 			return true
 		}
 	}
@@ -65,108 +113,200 @@ func RemoveLoader(loader ResLoader) bool {
 }
 
 // Removes a loader by file extension.
+// A loader accepting multiple extensions is removed for all of them.
 // Returns false if loader could not be found.
 func RemoveLoaderByExt(ext string) bool {
-	ext = strings.ToLower(ext)
+	return defaultEngine.RemoveLoaderByExt(ext)
+}
 
-	for i, l := range resloader {
-		if strings.ToLower(l.Ext()) == ext {
-			resloader = append(resloader[:i], resloader[i+1:]...)
-			log.Print("Removed loader for " + ext + " files")
-			return true
-		}
+// Removes a loader by file extension.
+// A loader accepting multiple extensions is removed for all of them.
+// Returns false if loader could not be found.
+func (e *Engine) RemoveLoaderByExt(ext string) bool {
+	loader := e.GetLoaderByExt(ext)
+
+	if loader == nil {
+		return false
 	}
 
-	return false
+	return e.RemoveLoader(loader)
 }
 
 // Removes all loaders.
 func RemoveAllLoaders() {
-	resloader = make([]ResLoader, 0)
+	defaultEngine.RemoveAllLoaders()
+}
+
+// Removes all loaders.
+func (e *Engine) RemoveAllLoaders() {
+	e.resloader = make([]ResLoader, 0)
 	log.Print("Cleared loaders")
 }
 
 // Returns a loader by file extension.
 // If not found, nil will be returned.
 func GetLoaderByExt(ext string) ResLoader {
+	return defaultEngine.GetLoaderByExt(ext)
+}
+
+// Returns a loader by file extension.
+// If not found, nil will be returned.
+func (e *Engine) GetLoaderByExt(ext string) ResLoader {
 	ext = strings.ToLower(ext)
 
-	for _, l := range resloader {
-		if strings.ToLower(l.Ext()) == ext {
-			return l
+	for _, l := range e.resloader {
+		for _, loaderExt := range loaderExts(l) {
+			if loaderExt == ext {
+				return l
+			}
 		}
 	}
 
 	return nil
 }
 
+// Returns the file extensions accepted by a loader in lower case.
+func loaderExts(loader ResLoader) []string {
+	if multi, ok := loader.(MultiExtResLoader); ok {
+		exts := make([]string, 0, len(multi.Exts()))
+
+		for _, ext := range multi.Exts() {
+			exts = append(exts, strings.ToLower(ext))
+		}
+
+		return exts
+	}
+
+	return []string{strings.ToLower(loader.Ext())}
+}
+
 // Loads a resource by file path.
-// If no loader is present for given file, an error will be returned.
+// If no loader is present for given file, an error matching ErrNoLoader will be returned.
 // If the loader fails to load the resource, an error will be returned.
-// If the resource name exists already, an error AND the resource will be returned.
-// This is allows to cleanup on failure.
+// Malformed files are reported as *ParseError.
+// If the resource name exists already, the resource is dropped and an error matching ErrDuplicateName will be returned.
+// Use errors.Is() and errors.As() to inspect errors.
 func LoadRes(path string) (Res, error) {
-	ext := filepath.Ext(path)
+	return defaultEngine.LoadRes(path)
+}
 
-	if len(ext) > 0 {
-		ext = ext[1:]
+// Loads a resource by file path.
+// If no loader is present for given file, an error matching ErrNoLoader will be returned.
+// If the loader fails to load the resource, an error will be returned.
+// Malformed files are reported as *ParseError.
+// If the resource name exists already, the resource is dropped and an error matching ErrDuplicateName will be returned.
+// Use errors.Is() and errors.As() to inspect errors.
+func (e *Engine) LoadRes(path string) (Res, error) {
+	return e.loadRes(path, filepath.Base(path), nil)
+}
+
+// Loads a resource by file path and adds it using given name.
+// Options are passed to the loader, if set. See ConfigurableResLoader for details.
+func (e *Engine) loadRes(path, name string, options json.RawMessage) (Res, error) {
+	res, err := e.loadFile(path, options)
+
+	if err != nil {
+		return nil, err
 	}
 
-	loader := GetLoaderByExt(ext)
+	res, err = e.addRes(res, name, path, fileExt(path))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// remembered to reload the resource the same way
+	if options != nil {
+		e.resOptions[res] = options
+	}
+
+	return res, nil
+}
+
+// Loads a file using the loader for its extension and options (can be nil), without adding the resource.
+func (e *Engine) loadFile(path string, options json.RawMessage) (Res, error) {
+	ext := fileExt(path)
+	loader := e.GetLoaderByExt(ext)
 
 	if loader == nil {
-		return nil, errors.New("No loader available for file extension " + ext)
+		return nil, &fileError{path, ErrNoLoader}
 	}
 
-	res, err := loader.Load(path)
+	file, err := e.OpenFile(path)
 
 	if err != nil {
 		return nil, err
 	}
 
-	res.SetName(filepath.Base(path))
-	res.SetPath(path)
-	res.SetExt(ext)
+	defer file.Close()
+	var res Res
 
-	for _, r := range resources {
-		if r.GetName() == res.GetName() {
-			return res, errors.New("Resource with file name " + res.GetName() + " exists already")
-		}
+	// options are ignored by loaders not accepting them,
+	// so that they can be set for folders containing different file types
+	if configurable, ok := loader.(ConfigurableResLoader); ok && options != nil {
+		res, err = configurable.LoadWithOptions(e.newResSource(file, path), options)
+	} else {
+		res, err = loader.Load(e.newResSource(file, path))
 	}
 
-	resources = append(resources, res)
-	log.Print("Loaded resource: " + res.GetName())
+	if err != nil {
+		return nil, loadError(path, err)
+	}
 
 	return res, nil
 }
 
-// Loads all files from given folder path.
-// If a loader is missing or fails to load the resource, an error will be returned.
-// All resources will be kept until an error occures.
-func LoadResFromFolder(path string) error {
-	log.Print("Loading resources from: " + path)
-	dir, err := ioutil.ReadDir(path)
+// Returns the file extension of path without leading dot.
+func fileExt(path string) string {
+	ext := filepath.Ext(path)
 
-	if err != nil {
-		return err
+	if len(ext) > 0 {
+		ext = ext[1:]
 	}
 
-	for _, file := range dir {
-		if file.IsDir() {
-			continue
-		}
+	return ext
+}
 
-		if _, err := LoadRes(filepath.Join(path, file.Name())); err != nil {
-			return err
+// Sets name, path and extension of a loaded resource and adds it.
+// If the resource name exists already, the resource is dropped and an error is returned.
+func (e *Engine) addRes(res Res, name, path, ext string) (Res, error) {
+	for _, r := range e.resources {
+		if r.GetName() == name {
+			dropRes(res)
+			return nil, &fileError{path, ErrDuplicateName}
 		}
 	}
 
-	return nil
+	res.SetName(name)
+	res.SetPath(path)
+	res.SetExt(ext)
+
+	e.resources = append(e.resources, res)
+	log.Print("Loaded resource: " + res.GetName())
+
+	return res, nil
+}
+
+// Loads all files from given folder path.
+// Subfolders are skipped. See LoadResFromFolderWithOptions() for details.
+func LoadResFromFolder(path string) error {
+	return defaultEngine.LoadResFromFolder(path)
+}
+
+// Loads all files from given folder path.
+// Subfolders are skipped. See LoadResFromFolderWithOptions() for details.
+func (e *Engine) LoadResFromFolder(path string) error {
+	return e.LoadResFromFolderWithOptions(path, nil)
 }
 
 // Returns a resource by name or nil, if not found.
 func GetResByName(name string) Res {
-	for _, r := range resources {
+	return defaultEngine.GetResByName(name)
+}
+
+// Returns a resource by name or nil, if not found.
+func (e *Engine) GetResByName(name string) Res {
+	for _, r := range e.resources {
 		if r.GetName() == name {
 			return r
 		}
@@ -177,7 +317,12 @@ func GetResByName(name string) Res {
 
 // Returns a resource by path or nil, if not found.
 func GetResByPath(path string) Res {
-	for _, r := range resources {
+	return defaultEngine.GetResByPath(path)
+}
+
+// Returns a resource by path or nil, if not found.
+func (e *Engine) GetResByPath(path string) Res {
+	for _, r := range e.resources {
 		if r.GetPath() == path {
 			return r
 		}
@@ -187,11 +332,20 @@ func GetResByPath(path string) Res {
 }
 
 // Removes a resource by name.
+// The resource is not dropped, use UnloadRes() to free GL objects.
 // Returns false if resource could not be found.
 func RemoveResByName(name string) bool {
-	for i, r := range resources {
+	return defaultEngine.RemoveResByName(name)
+}
+
+// Removes a resource by name.
+// Returns false if resource could not be found.
+func (e *Engine) RemoveResByName(name string) bool {
+	for i, r := range e.resources {
 		if r.GetName() == name {
-			resources = append(resources[:i], resources[i+1:]...)
+			e.resources = append(e.resources[:i], e.resources[i+1:]...)
+			delete(e.resRefs, r)
+			delete(e.resOptions, r)
 			log.Print("Removed resource: " + r.GetName())
 			return true
 		}
@@ -201,11 +355,20 @@ func RemoveResByName(name string) bool {
 }
 
 // Removes a resource by path.
+// The resource is not dropped, use UnloadRes() to free GL objects.
 // Returns false if resource could not be found.
 func RemoveResByPath(path string) bool {
-	for i, r := range resources {
+	return defaultEngine.RemoveResByPath(path)
+}
+
+// Removes a resource by path.
+// Returns false if resource could not be found.
+func (e *Engine) RemoveResByPath(path string) bool {
+	for i, r := range e.resources {
 		if r.GetPath() == path {
-			resources = append(resources[:i], resources[i+1:]...)
+			e.resources = append(e.resources[:i], e.resources[i+1:]...)
+			delete(e.resRefs, r)
+			delete(e.resOptions, r)
 			log.Print("Removed resource: " + r.GetName())
 			return true
 		}
@@ -216,6 +379,13 @@ func RemoveResByPath(path string) bool {
 
 // Removes all resources.
 func RemoveAllRes() {
-	resources = make([]Res, 0)
+	defaultEngine.RemoveAllRes()
+}
+
+// Removes all resources.
+func (e *Engine) RemoveAllRes() {
+	e.resources = make([]Res, 0)
+	e.resRefs = make(map[Res]int)
+	e.resOptions = make(map[Res]json.RawMessage)
 	log.Print("Cleared resources")
 }
@@ -0,0 +1,284 @@
+package goga
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+)
+
+const (
+	async_res_workers          = 4
+	default_async_upload_limit = 4
+)
+
+// A resource loader implementing this interface can be used to load resources asynchronously.
+// Decode is called on a worker goroutine and must not call GL functions,
+// it reads and decodes the file. Upload is called on the main thread with the decoded data
+// and creates the GL objects of the resource.
+// Loaders not implementing this interface are called on the main thread when loaded asynchronously.
+type AsyncResLoader interface {
+	ResLoader
+	Decode(io.Reader) (interface{}, error)
+	Upload(interface{}) (Res, error)
+}
+
+// Result of decoding a file on a worker goroutine.
+type asyncResult struct {
+	path   string
+	name   string
+	ext    string
+	loader ResLoader
+	data   interface{}
+	err    error
+}
+
+// Handle to resources loaded asynchronously.
+// It can be polled each frame to display the loading progress.
+// All functions must be called on the main thread.
+type ResLoadHandle struct {
+	results chan asyncResult
+	pending int
+	total   int
+	loaded  int
+	res     []Res
+	errors  []error
+}
+
+// Loads resources by file path asynchronously.
+// Files are opened from the virtual file system as mounted on the calling thread,
+// read and decoded on worker goroutines and GL objects are created on the main thread
+// at the beginning of each frame, limited by SetAsyncUploadLimit().
+// Loaded resources are added the same way as by LoadRes(), errors are collected by the returned handle.
+func LoadResAsync(paths ...string) *ResLoadHandle {
+	return defaultEngine.LoadResAsync(paths...)
+}
+
+// Loads resources by file path asynchronously.
+// See LoadResAsync() for details.
+func (e *Engine) LoadResAsync(paths ...string) *ResLoadHandle {
+	files := make([]resFile, 0, len(paths))
+
+	for _, path := range paths {
+		files = append(files, resFile{path, filepath.Base(path)})
+	}
+
+	return e.loadResAsync(files)
+}
+
+// Loads given files asynchronously.
+func (e *Engine) loadResAsync(files []resFile) *ResLoadHandle {
+	handle := &ResLoadHandle{}
+	handle.results = make(chan asyncResult, len(files))
+	handle.total = len(files)
+	handle.res = make([]Res, 0, len(files))
+	handle.errors = make([]error, 0)
+	mounts := make([]mount, len(e.mounts))
+	copy(mounts, e.mounts)
+
+	for _, f := range files {
+		path, name := f.path, f.name
+		ext := fileExt(path)
+		loader := e.GetLoaderByExt(ext)
+
+		if loader == nil {
+			handle.finish(nil, &fileError{path, ErrNoLoader})
+			continue
+		}
+
+		handle.pending++
+		async, ok := loader.(AsyncResLoader)
+
+		if !ok {
+			// opened and loaded on upload, so no file is held open meanwhile
+			handle.results <- asyncResult{path: path, name: name, ext: ext, loader: loader}
+			continue
+		}
+
+		go func(path, name, ext string) {
+			e.asyncWorkers <- true
+			data, err := decodeAsync(async, mounts, path)
+			<-e.asyncWorkers
+
+			handle.results <- asyncResult{path, name, ext, loader, data, err}
+		}(path, name, ext)
+	}
+
+	if handle.pending > 0 {
+		e.asyncHandles = append(e.asyncHandles, handle)
+	}
+
+	return handle
+}
+
+// Opens and decodes a file from given mounts on a worker goroutine.
+func decodeAsync(loader AsyncResLoader, mounts []mount, path string) (interface{}, error) {
+	file, err := openFile(mounts, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return loader.Decode(&ResSource{file, path, mounts})
+}
+
+// Loads all files from given folder path asynchronously.
+// Files are selected and named the same way as by LoadResFromFolderWithOptions(), options can be nil.
+// Returns an error if the folder could not be read.
+// See LoadResAsync() for details.
+func LoadResFromFolderAsync(path string, options *FolderOptions) (*ResLoadHandle, error) {
+	return defaultEngine.LoadResFromFolderAsync(path, options)
+}
+
+// Loads all files from given folder path asynchronously.
+// See LoadResFromFolderAsync() for details.
+func (e *Engine) LoadResFromFolderAsync(path string, options *FolderOptions) (*ResLoadHandle, error) {
+	log.Print("Loading resources asynchronously from: " + path)
+	files, err := e.listResFiles(path, "", options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.loadResAsync(files), nil
+}
+
+// Sets the maximum number of resources uploaded per frame.
+// A limit of 0 or less uploads all decoded resources within the next frame.
+// Default is 4.
+func SetAsyncUploadLimit(limit int) {
+	defaultEngine.SetAsyncUploadLimit(limit)
+}
+
+// Sets the maximum number of resources uploaded per frame.
+// See SetAsyncUploadLimit() for details.
+func (e *Engine) SetAsyncUploadLimit(limit int) {
+	e.asyncUploadLimit = limit
+}
+
+// Blocks until all resources loaded asynchronously are decoded and uploads them.
+// Must be called on the main thread.
+func FinishAsyncRes() {
+	defaultEngine.FinishAsyncRes()
+}
+
+// Blocks until all resources loaded asynchronously are decoded and uploads them.
+// Must be called on the main thread.
+func (e *Engine) FinishAsyncRes() {
+	for _, handle := range e.asyncHandles {
+		for handle.pending > 0 {
+			e.uploadAsyncResult(handle, <-handle.results)
+		}
+	}
+
+	e.asyncHandles = e.asyncHandles[:0]
+}
+
+// Uploads decoded resources, up to the upload limit.
+// Called at the beginning of each frame.
+func (e *Engine) uploadAsyncRes() {
+	uploaded := 0
+	i := 0
+
+	for i < len(e.asyncHandles) {
+		handle := e.asyncHandles[i]
+
+		for handle.pending > 0 && (e.asyncUploadLimit <= 0 || uploaded < e.asyncUploadLimit) {
+			if !e.receiveAsyncResult(handle) {
+				break
+			}
+
+			uploaded++
+		}
+
+		if handle.pending == 0 {
+			e.asyncHandles = append(e.asyncHandles[:i], e.asyncHandles[i+1:]...)
+		} else {
+			i++
+		}
+	}
+}
+
+// Uploads the next decoded resource of handle without blocking.
+// Returns false if no resource is decoded yet.
+func (e *Engine) receiveAsyncResult(handle *ResLoadHandle) bool {
+	select {
+	case result := <-handle.results:
+		e.uploadAsyncResult(handle, result)
+		return true
+	default:
+		return false
+	}
+}
+
+// Creates the resource from decoded data and adds it.
+func (e *Engine) uploadAsyncResult(handle *ResLoadHandle, result asyncResult) {
+	handle.pending--
+
+	if result.err != nil {
+		handle.finish(nil, loadError(result.path, result.err))
+		return
+	}
+
+	var res Res
+	var err error
+
+	if async, ok := result.loader.(AsyncResLoader); ok {
+		res, err = async.Upload(result.data)
+
+		if err != nil {
+			err = loadError(result.path, err)
+		}
+	} else {
+		res, err = e.loadFile(result.path, nil)
+	}
+
+	if err == nil {
+		res, err = e.addRes(res, result.name, result.path, result.ext)
+	}
+
+	handle.finish(res, err)
+}
+
+// Counts a resource as loaded, storing the resource or error.
+func (h *ResLoadHandle) finish(res Res, err error) {
+	h.loaded++
+
+	if err != nil {
+		log.Print("Error loading resource asynchronously: " + err.Error())
+		h.errors = append(h.errors, err)
+	} else {
+		h.res = append(h.res, res)
+	}
+}
+
+// Returns the number of resources loaded (including failed ones) and the total number of resources.
+func (h *ResLoadHandle) GetProgress() (int, int) {
+	return h.loaded, h.total
+}
+
+// Returns the loading progress between 0 and 1.
+func (h *ResLoadHandle) GetRatio() float64 {
+	if h.total == 0 {
+		return 1
+	}
+
+	return float64(h.loaded) / float64(h.total)
+}
+
+// Returns true if all resources are loaded or failed to load.
+func (h *ResLoadHandle) IsDone() bool {
+	return h.loaded == h.total
+}
+
+// Returns the resources loaded successfully so far.
+func (h *ResLoadHandle) GetRes() []Res {
+	return h.res
+}
+
+// Returns the errors occurred so far.
+// Use LoadErrors(handle.GetErrors()) to report them as single error.
+func (h *ResLoadHandle) GetErrors() []error {
+	return h.errors
+}
@@ -0,0 +1,100 @@
+package goga
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+type testAsyncResLoader struct {
+	testResLoader
+}
+
+func (l *testAsyncResLoader) Decode(r io.Reader) (interface{}, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "invalid" {
+		return nil, errors.New("Invalid test resource")
+	}
+
+	return string(data), nil
+}
+
+func (l *testAsyncResLoader) Upload(data interface{}) (Res, error) {
+	return &testRes{content: data.(string)}, nil
+}
+
+func (l *testAsyncResLoader) Ext() string {
+	return "async"
+}
+
+func newTestAsyncEngine() *Engine {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{
+		"a.txt":         &fstest.MapFile{Data: []byte("a")},
+		"b.txt":         &fstest.MapFile{Data: []byte("b")},
+		"c.txt":         &fstest.MapFile{Data: []byte("c")},
+		"d.async":       &fstest.MapFile{Data: []byte("d")},
+		"invalid.async": &fstest.MapFile{Data: []byte("invalid")},
+		"e.unknown":     &fstest.MapFile{Data: []byte("e")},
+	})
+	e.AddLoader(&testResLoader{})
+	e.AddLoader(&testAsyncResLoader{})
+	return e
+}
+
+func TestLoadResAsyncUploadLimit(t *testing.T) {
+	e := newTestAsyncEngine()
+	e.SetAsyncUploadLimit(2)
+	handle := e.LoadResAsync("a.txt", "b.txt", "c.txt")
+
+	if loaded, total := handle.GetProgress(); loaded != 0 || total != 3 || handle.IsDone() {
+		t.Fatalf("Expected nothing to be loaded before the first frame, got %v/%v", loaded, total)
+	}
+
+	e.uploadAsyncRes()
+
+	if loaded, _ := handle.GetProgress(); loaded != 2 || handle.IsDone() || handle.GetRatio() != 2.0/3.0 {
+		t.Fatalf("Expected 2 resources to be uploaded within the first frame, got %v", loaded)
+	}
+
+	e.uploadAsyncRes()
+
+	if loaded, _ := handle.GetProgress(); loaded != 3 || !handle.IsDone() || len(e.asyncHandles) != 0 {
+		t.Fatalf("Expected all resources to be uploaded within the second frame, got %v", loaded)
+	}
+
+	if len(handle.GetRes()) != 3 || e.GetResByName("c.txt") == nil {
+		t.Fatalf("Expected resources to be added, got %v", handle.GetRes())
+	}
+}
+
+func TestLoadResAsyncErrors(t *testing.T) {
+	e := newTestAsyncEngine()
+	handle := e.LoadResAsync("d.async", "invalid.async", "missing.async", "e.unknown", "missing.txt")
+	e.FinishAsyncRes()
+
+	if !handle.IsDone() || len(e.asyncHandles) != 0 {
+		t.Fatal("Expected all resources to be done")
+	}
+
+	if res := handle.GetRes(); len(res) != 1 || res[0].(*testRes).content != "d" || e.GetResByName("d.async") == nil {
+		t.Fatalf("Expected decoded resource to be uploaded, got %v", res)
+	}
+
+	errs := handle.GetErrors()
+
+	if len(errs) != 4 {
+		t.Fatalf("Expected 4 errors, got %v", errs)
+	}
+
+	if err := LoadErrors(errs); !errors.Is(err, ErrNoLoader) || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected missing loader and missing file errors, got %v", err)
+	}
+}
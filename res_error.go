@@ -0,0 +1,101 @@
+package goga
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Returned when no loader is available for the file extension of a resource.
+	ErrNoLoader = errors.New("No loader available for file extension")
+
+	// Returned when a resource with the same name exists already.
+	ErrDuplicateName = errors.New("Resource with same name exists already")
+)
+
+// Error returned by loaders for malformed files.
+// Line starts at 1 and is 0 if unknown, like for binary files.
+// The file is set when the resource is loaded by path.
+type ParseError struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+// Returns the error formatted as file:line: reason.
+func (e *ParseError) Error() string {
+	if e.File != "" && e.Line > 0 {
+		return e.File + ":" + strconv.Itoa(e.Line) + ": " + e.Reason
+	} else if e.File != "" {
+		return e.File + ": " + e.Reason
+	} else if e.Line > 0 {
+		return "line " + strconv.Itoa(e.Line) + ": " + e.Reason
+	}
+
+	return e.Reason
+}
+
+// Errors collected while loading multiple resources.
+// Inspecting them using errors.Is() and errors.As() requires Go 1.20 or newer,
+// older versions don't unwrap multiple errors.
+type LoadErrors []error
+
+// Returns all errors, separated by new line.
+func (e LoadErrors) Error() string {
+	msg := make([]string, 0, len(e))
+
+	for _, err := range e {
+		msg = append(msg, err.Error())
+	}
+
+	return strconv.Itoa(len(e)) + " error(s) loading resources:\n" + strings.Join(msg, "\n")
+}
+
+// Returns the collected errors, so they can be inspected using errors.Is() and errors.As().
+func (e LoadErrors) Unwrap() []error {
+	return e
+}
+
+// Error loading a file, wrapping the cause.
+type fileError struct {
+	path string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// Adds the file path to an error returned by a loader.
+// Parse errors get their file set, other errors are wrapped unless they contain the path already.
+func loadError(path string, err error) error {
+	var parseErr *ParseError
+	var pathErr *fs.PathError
+
+	if errors.As(err, &parseErr) {
+		if parseErr.File == "" {
+			parseErr.File = path
+		}
+
+		return err
+	}
+
+	if errors.As(err, &pathErr) {
+		return err
+	}
+
+	return &fileError{path, err}
+}
+
+// Drops a resource not added because of an error, if it is dropable.
+func dropRes(res Res) {
+	if drop, ok := res.(Dropable); ok {
+		drop.Drop()
+	}
+}
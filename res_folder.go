@@ -0,0 +1,126 @@
+package goga
+
+import (
+	"encoding/json"
+	"log"
+	"path"
+	"path/filepath"
+)
+
+// Options to load resources from folder.
+// Include and exclude are glob patterns (see path.Match) matched against the path relative
+// to the folder (like "enemies/*.png") and against the file name (like "*.png").
+// If include is empty, all files are included. Excluded files are skipped even if included.
+type FolderOptions struct {
+	Recursive bool
+	Include   []string
+	Exclude   []string
+}
+
+// A file to load as resource and its resource name.
+type resFile struct {
+	path string
+	name string
+}
+
+// Loads all files from given folder path matching the options.
+// Resources are named by their path relative to the folder, using slashes (like "enemies/idle.png").
+// Loading continues if a file cannot be loaded, all errors are returned as LoadErrors.
+// Files without loader are reported as errors, use exclude patterns to skip them.
+// Options can be nil, which loads all files of the folder, without subfolders.
+func LoadResFromFolderWithOptions(path string, options *FolderOptions) error {
+	return defaultEngine.LoadResFromFolderWithOptions(path, options)
+}
+
+// Loads all files from given folder path matching the options.
+// See LoadResFromFolderWithOptions() for details.
+func (e *Engine) LoadResFromFolderWithOptions(path string, options *FolderOptions) error {
+	log.Print("Loading resources from: " + path)
+	files, err := e.listResFiles(path, "", options)
+
+	if err != nil {
+		return err
+	}
+
+	return e.loadResFiles(files, nil)
+}
+
+// Loads given files and collects errors.
+func (e *Engine) loadResFiles(files []resFile, options json.RawMessage) error {
+	errs := make(LoadErrors, 0)
+
+	for _, file := range files {
+		if _, err := e.loadRes(file.path, file.name, options); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Returns the files of folder matching the options.
+// Names are prefixed by given prefix.
+func (e *Engine) listResFiles(dir, prefix string, options *FolderOptions) ([]resFile, error) {
+	if options == nil {
+		options = &FolderOptions{}
+	}
+
+	entries, err := e.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]resFile, 0, len(entries))
+
+	for _, entry := range entries {
+		name := path.Join(prefix, entry.Name())
+
+		if entry.IsDir() {
+			if !options.Recursive {
+				continue
+			}
+
+			sub, err := e.listResFiles(filepath.Join(dir, entry.Name()), name, options)
+
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, sub...)
+		} else if options.matches(name) {
+			files = append(files, resFile{filepath.Join(dir, entry.Name()), name})
+		}
+	}
+
+	return files, nil
+}
+
+// Returns true if given relative path is included and not excluded.
+func (o *FolderOptions) matches(name string) bool {
+	if len(o.Include) > 0 && !matchGlobs(o.Include, name) {
+		return false
+	}
+
+	return !matchGlobs(o.Exclude, name)
+}
+
+// Returns true if any of the patterns matches the path or its file name.
+// Invalid patterns never match.
+func matchGlobs(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package goga
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestFolderEngine() *Engine {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{
+		"res/a.txt":               &fstest.MapFile{Data: []byte("a")},
+		"res/b.txt":               &fstest.MapFile{Data: []byte("b")},
+		"res/enemies/idle.txt":    &fstest.MapFile{Data: []byte("enemy")},
+		"res/enemies/old.txt":     &fstest.MapFile{Data: []byte("old")},
+		"res/player/idle.txt":     &fstest.MapFile{Data: []byte("player")},
+		"res/player/deep/run.txt": &fstest.MapFile{Data: []byte("run")},
+	})
+	e.AddLoader(&testResLoader{})
+	return e
+}
+
+func getResNames(e *Engine) []string {
+	names := make([]string, 0, len(e.resources))
+
+	for _, res := range e.resources {
+		names = append(names, res.GetName())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestLoadResFromFolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *FolderOptions
+		names   []string
+	}{
+		{"default", nil, []string{"a.txt", "b.txt"}},
+		{"recursive", &FolderOptions{Recursive: true},
+			[]string{"a.txt", "b.txt", "enemies/idle.txt", "enemies/old.txt", "player/deep/run.txt", "player/idle.txt"}},
+		{"include relative path", &FolderOptions{Recursive: true, Include: []string{"enemies/*"}},
+			[]string{"enemies/idle.txt", "enemies/old.txt"}},
+		{"include file name", &FolderOptions{Recursive: true, Include: []string{"idle.*"}},
+			[]string{"enemies/idle.txt", "player/idle.txt"}},
+		{"exclude", &FolderOptions{Recursive: true, Include: []string{"*.txt"}, Exclude: []string{"old.txt", "player/*/*"}},
+			[]string{"a.txt", "b.txt", "enemies/idle.txt", "player/idle.txt"}},
+		{"invalid pattern", &FolderOptions{Include: []string{"["}}, []string{}},
+	}
+
+	for _, test := range tests {
+		e := newTestFolderEngine()
+
+		if err := e.LoadResFromFolderWithOptions("res", test.options); err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+
+		if names := getResNames(e); !equalStrings(names, test.names) {
+			t.Fatalf("%v: expected resources %v, got %v", test.name, test.names, names)
+		}
+	}
+}
+
+func TestLoadResFromFolderPaths(t *testing.T) {
+	e := newTestFolderEngine()
+
+	if err := e.LoadResFromFolderWithOptions("res", &FolderOptions{Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	enemy := e.GetResByName("enemies/idle.txt").(*testRes)
+	player := e.GetResByName("player/idle.txt").(*testRes)
+
+	if enemy.content != "enemy" || player.content != "player" || enemy.GetPath() != "res/enemies/idle.txt" {
+		t.Fatalf("Expected resources with the same file name to be kept apart, got %v and %v", *enemy, *player)
+	}
+}
+
+func TestLoadResFromFolderErrors(t *testing.T) {
+	e := newTestFolderEngine()
+	e.MountFS(fstest.MapFS{"res/c.png": &fstest.MapFile{Data: []byte("c")}})
+
+	if _, err := e.loadRes("res/enemies/old.txt", "b.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := e.LoadResFromFolderWithOptions("res", nil)
+	var errs LoadErrors
+
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected all errors to be collected, got %v", err)
+	}
+
+	if !errors.Is(err, ErrNoLoader) || !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("Expected missing loader and duplicate name errors, got %v", err)
+	}
+
+	if e.GetResByName("a.txt") == nil {
+		t.Fatal("Expected loading to continue after errors")
+	}
+
+	if err := e.LoadResFromFolderWithOptions("missing", nil); err == nil {
+		t.Fatal("Expected error for missing folder")
+	}
+}
@@ -0,0 +1,241 @@
+package goga
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// A resource loader implementing this interface accepts options from resource manifests.
+// The options are the raw JSON value of the manifest entry and must not change the loader itself.
+type ConfigurableResLoader interface {
+	ResLoader
+	LoadWithOptions(io.Reader, json.RawMessage) (Res, error)
+}
+
+// Resource manifest format:
+//
+//	{
+//	    "groups": {
+//	        "menu": [
+//	            {"path": "menu/logo.png"},
+//	            {"path": "fonts/victor.png", "name": "victor.png", "options": {"filter": "nearest", "keepData": true}},
+//	            {"folder": "menu/buttons", "recursive": true, "include": ["*.png"], "exclude": ["*_old.png"]}
+//	        ],
+//	        ...
+//	    }
+//	}
+//
+// Each entry is either a file (path) or a folder. Paths are relative to the manifest file.
+// Resources are named by their path relative to the manifest file, unless a name is set for a file.
+// Options are passed to loaders implementing ConfigurableResLoader, for all files of the entry.
+// Loaders not implementing it ignore the options.
+type jsonManifest struct {
+	Groups map[string][]jsonManifestEntry `json:"groups"`
+}
+
+type jsonManifestEntry struct {
+	Path      string          `json:"path"`
+	Name      string          `json:"name"`
+	Folder    string          `json:"folder"`
+	Recursive bool            `json:"recursive"`
+	Include   []string        `json:"include"`
+	Exclude   []string        `json:"exclude"`
+	Options   json.RawMessage `json:"options"`
+}
+
+// A group of resources defined by a manifest.
+type resGroup struct {
+	root    string
+	entries []jsonManifestEntry
+	res     []Res
+}
+
+// Reads a resource manifest from the virtual file system and adds its groups.
+// Resources are not loaded until their group is loaded using LoadResGroup().
+// Existing groups with the same name are replaced.
+func LoadResManifest(file string) error {
+	return defaultEngine.LoadResManifest(file)
+}
+
+// Reads a resource manifest from the virtual file system and adds its groups.
+// See LoadResManifest() for details.
+func (e *Engine) LoadResManifest(file string) error {
+	data, err := e.ReadFile(file)
+
+	if err != nil {
+		return err
+	}
+
+	manifest := jsonManifest{}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return errors.New("Manifest " + file + ": " + err.Error())
+	}
+
+	for name, entries := range manifest.Groups {
+		for _, entry := range entries {
+			if (entry.Path == "") == (entry.Folder == "") {
+				return errors.New("Manifest " + file + ", group " + name + ": entry must have either path or folder")
+			}
+		}
+	}
+
+	root := path.Dir(filepath.ToSlash(file))
+
+	for name, entries := range manifest.Groups {
+		e.resGroups[name] = &resGroup{root, entries, make([]Res, 0)}
+	}
+
+	log.Print("Loaded resource manifest: " + file)
+
+	return nil
+}
+
+// Loads all resources of a group added by a manifest.
+// The resources are acquired by the group (see AcquireRes()), so groups can share resources.
+// Resources loaded by another group are acquired instead of loaded again, resources loaded using LoadRes() are skipped.
+// Loading the group again skips resources it loaded already.
+// Loading continues if a file cannot be loaded, all errors are returned as LoadErrors.
+func LoadResGroup(name string) error {
+	return defaultEngine.LoadResGroup(name)
+}
+
+// Loads all resources of a group added by a manifest.
+// See LoadResGroup() for details.
+func (e *Engine) LoadResGroup(name string) error {
+	group, ok := e.resGroups[name]
+
+	if !ok {
+		return errors.New("Resource group not found: " + name)
+	}
+
+	log.Print("Loading resource group: " + name)
+	errs := make(LoadErrors, 0)
+
+	for _, entry := range group.entries {
+		files, err := e.listManifestEntry(group.root, entry)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, file := range files {
+			res := e.GetResByName(file.name)
+
+			if res != nil && res.GetPath() == file.path {
+				// loaded globally or by this group already
+				if e.resRefs[res] == 0 || group.has(res) {
+					continue
+				}
+			} else {
+				res, err = e.loadRes(file.path, file.name, entry.Options)
+
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+
+			e.AcquireRes(res.GetName())
+			group.res = append(group.res, res)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Returns the files of a manifest entry.
+func (e *Engine) listManifestEntry(root string, entry jsonManifestEntry) ([]resFile, error) {
+	if entry.Path != "" {
+		name := path.Clean(filepath.ToSlash(entry.Path))
+
+		if entry.Name != "" {
+			name = entry.Name
+		}
+
+		return []resFile{{path.Join(root, filepath.ToSlash(entry.Path)), name}}, nil
+	}
+
+	folder := path.Clean(filepath.ToSlash(entry.Folder))
+	options := &FolderOptions{entry.Recursive, entry.Include, entry.Exclude}
+
+	return e.listResFiles(path.Join(root, folder), folder, options)
+}
+
+// Releases all resources acquired by a group (see ReleaseRes()).
+// Resources not acquired by other groups or scenes are removed and dropped.
+// Returns false if the group could not be found.
+func UnloadResGroup(name string) bool {
+	return defaultEngine.UnloadResGroup(name)
+}
+
+// Releases all resources acquired by a group (see ReleaseRes()).
+// See UnloadResGroup() for details.
+func (e *Engine) UnloadResGroup(name string) bool {
+	group, ok := e.resGroups[name]
+
+	if !ok {
+		return false
+	}
+
+	for _, res := range group.res {
+		e.ReleaseRes(res)
+	}
+
+	group.res = make([]Res, 0)
+
+	return true
+}
+
+// Returns the resources loaded by a group or nil if the group could not be found.
+func GetResGroup(name string) []Res {
+	return defaultEngine.GetResGroup(name)
+}
+
+// Returns the resources loaded by a group or nil if the group could not be found.
+func (e *Engine) GetResGroup(name string) []Res {
+	if group, ok := e.resGroups[name]; ok {
+		return group.res
+	}
+
+	return nil
+}
+
+// Returns true if the resource was acquired by the group.
+func (g *resGroup) has(res Res) bool {
+	for _, r := range g.res {
+		if r == res {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the names of all resource groups in alphabetical order.
+func GetResGroupNames() []string {
+	return defaultEngine.GetResGroupNames()
+}
+
+// Returns the names of all resource groups in alphabetical order.
+func (e *Engine) GetResGroupNames() []string {
+	names := make([]string, 0, len(e.resGroups))
+
+	for name := range e.resGroups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,141 @@
+package goga
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+const test_manifest = `{
+	"groups": {
+		"menu": [
+			{"path": "menu/logo.txt"},
+			{"path": "shared.txt", "name": "font", "options": {"filter": "nearest"}},
+			{"folder": "menu/buttons", "recursive": true, "include": ["*.txt"], "exclude": ["*_old.txt"]}
+		],
+		"level": [
+			{"path": "shared.txt", "name": "font"},
+			{"path": "level/map.txt"}
+		],
+		"duplicate": [
+			{"path": "menu/logo.txt", "name": "logo"},
+			{"path": "level/map.txt", "name": "logo"}
+		]
+	}
+}`
+
+func newTestManifestEngine(t *testing.T) *Engine {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{
+		"res/manifest.json":              &fstest.MapFile{Data: []byte(test_manifest)},
+		"res/menu/logo.txt":              &fstest.MapFile{Data: []byte("logo")},
+		"res/menu/buttons/play.txt":      &fstest.MapFile{Data: []byte("play")},
+		"res/menu/buttons/play_old.txt":  &fstest.MapFile{Data: []byte("old")},
+		"res/menu/buttons/sub/quit.txt":  &fstest.MapFile{Data: []byte("quit")},
+		"res/menu/buttons/sub/quit.json": &fstest.MapFile{Data: []byte("{}")},
+		"res/shared.txt":                 &fstest.MapFile{Data: []byte("font")},
+		"res/level/map.txt":              &fstest.MapFile{Data: []byte("map")},
+	})
+	e.AddLoader(&testResLoader{})
+
+	if err := e.LoadResManifest("res/manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestLoadResGroup(t *testing.T) {
+	e := newTestManifestEngine(t)
+
+	if names := e.GetResGroupNames(); !equalStrings(names, []string{"duplicate", "level", "menu"}) {
+		t.Fatalf("Expected groups in alphabetical order, got %v", names)
+	}
+
+	if err := e.LoadResGroup("menu"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"font", "menu/buttons/play.txt", "menu/buttons/sub/quit.txt", "menu/logo.txt"}
+
+	if names := getResNames(e); !equalStrings(names, expected) || len(e.GetResGroup("menu")) != 4 {
+		t.Fatalf("Expected resources %v, got %v", expected, names)
+	}
+
+	if font := e.GetResByName("font").(*testRes); font.GetPath() != "res/shared.txt" || font.options != `{"filter": "nearest"}` {
+		t.Fatalf("Expected named resource to be loaded with options, got %v", *font)
+	}
+
+	if err := e.LoadResGroup("menu"); err != nil || len(e.GetResGroup("menu")) != 4 || e.GetResRefCount("font") != 1 {
+		t.Fatal("Expected group loaded twice to skip its resources")
+	}
+
+	if err := e.LoadResGroup("missing"); err == nil {
+		t.Fatal("Expected error for missing group")
+	}
+}
+
+func TestLoadResGroupDuplicateName(t *testing.T) {
+	e := newTestManifestEngine(t)
+	err := e.LoadResGroup("duplicate")
+
+	if !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("Expected duplicate name error, got %v", err)
+	}
+
+	if res := e.GetResByName("logo"); res == nil || res.GetPath() != "res/menu/logo.txt" {
+		t.Fatal("Expected first resource to be kept")
+	}
+}
+
+func TestUnloadResGroupRefCount(t *testing.T) {
+	e := newTestManifestEngine(t)
+
+	if err := e.LoadResGroup("menu"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.LoadResGroup("level"); err != nil {
+		t.Fatal(err)
+	}
+
+	font := e.GetResByName("font")
+
+	if e.GetResRefCount("font") != 2 || e.GetResRefCount("level/map.txt") != 1 {
+		t.Fatal("Expected shared resource to be acquired by both groups")
+	}
+
+	if !e.UnloadResGroup("menu") || e.GetResByName("font") != font || e.GetResByName("menu/logo.txt") != nil {
+		t.Fatal("Expected shared resource to be kept after unloading one group")
+	}
+
+	if len(e.GetResGroup("menu")) != 0 || e.GetResRefCount("font") != 1 {
+		t.Fatal("Expected group to release its resources")
+	}
+
+	if !e.UnloadResGroup("level") || e.GetResByName("font") != nil || len(e.resources) != 0 {
+		t.Fatal("Expected all resources to be dropped after unloading both groups")
+	}
+
+	if e.UnloadResGroup("missing") {
+		t.Fatal("Expected unloading missing group to fail")
+	}
+}
+
+func TestLoadResGroupSkipsGlobalRes(t *testing.T) {
+	e := newTestManifestEngine(t)
+
+	if _, err := e.loadRes("res/shared.txt", "font", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.LoadResGroup("level"); err != nil {
+		t.Fatal(err)
+	}
+
+	e.UnloadResGroup("level")
+
+	if e.GetResByName("font") == nil || e.GetResByName("level/map.txt") != nil {
+		t.Fatal("Expected resource loaded globally to be kept")
+	}
+}
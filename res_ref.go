@@ -0,0 +1,120 @@
+package goga
+
+import (
+	"log"
+)
+
+// Acquires a resource by name, increasing its reference count.
+// Acquired resources are dropped when they are released as often as they were acquired.
+// Resources never acquired are kept until they are unloaded or the program stops.
+// Returns nil if the resource could not be found.
+func AcquireRes(name string) Res {
+	return defaultEngine.AcquireRes(name)
+}
+
+// Acquires a resource by name, increasing its reference count.
+// See AcquireRes() for details.
+func (e *Engine) AcquireRes(name string) Res {
+	res := e.GetResByName(name)
+
+	if res != nil {
+		e.resRefs[res]++
+	}
+
+	return res
+}
+
+// Releases an acquired resource, decreasing its reference count.
+// When the count reaches zero, the resource is removed and dropped.
+// Returns false if the resource was not acquired.
+func ReleaseRes(res Res) bool {
+	return defaultEngine.ReleaseRes(res)
+}
+
+// Releases an acquired resource, decreasing its reference count.
+// See ReleaseRes() for details.
+func (e *Engine) ReleaseRes(res Res) bool {
+	refs, ok := e.resRefs[res]
+
+	if !ok {
+		return false
+	}
+
+	if refs > 1 {
+		e.resRefs[res] = refs - 1
+		return true
+	}
+
+	e.unloadRes(res)
+
+	return true
+}
+
+// Returns the reference count of a resource by name.
+// Returns 0 if the resource was not acquired or could not be found.
+func GetResRefCount(name string) int {
+	return defaultEngine.GetResRefCount(name)
+}
+
+// Returns the reference count of a resource by name.
+// Returns 0 if the resource was not acquired or could not be found.
+func (e *Engine) GetResRefCount(name string) int {
+	if res := e.GetResByName(name); res != nil {
+		return e.resRefs[res]
+	}
+
+	return 0
+}
+
+// Removes and drops a resource by name immediately, regardless of its reference count.
+// Returns false if resource could not be found.
+func UnloadRes(name string) bool {
+	return defaultEngine.UnloadRes(name)
+}
+
+// Removes and drops a resource by name immediately, regardless of its reference count.
+// Returns false if resource could not be found.
+func (e *Engine) UnloadRes(name string) bool {
+	res := e.GetResByName(name)
+
+	if res == nil {
+		return false
+	}
+
+	if refs := e.resRefs[res]; refs > 0 {
+		log.Printf("Unloading resource %v still referenced %v times", name, refs)
+	}
+
+	e.unloadRes(res)
+
+	return true
+}
+
+// Removes the resource and its reference count and drops it.
+func (e *Engine) unloadRes(res Res) {
+	for i, r := range e.resources {
+		if r == res {
+			e.resources = append(e.resources[:i], e.resources[i+1:]...)
+			break
+		}
+	}
+
+	// forget it for hot reload, so that it is not swapped after being dropped
+	delete(e.resRefs, res)
+	delete(e.resOptions, res)
+	delete(e.resStates, res)
+	delete(e.reloadCallbacks, res.GetName())
+
+	if drop, ok := res.(Dropable); ok {
+		drop.Drop()
+	}
+
+	log.Print("Unloaded resource: " + res.GetName())
+}
+
+// Logs resources which were acquired but never released.
+func (e *Engine) reportLeakedRes() {
+	for res, refs := range e.resRefs {
+		log.Printf("Warning: resource %v leaked, it was not released %v times", res.GetName(), refs)
+	}
+}
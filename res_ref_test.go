@@ -0,0 +1,172 @@
+package goga
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type testResGame struct {
+	engine *Engine
+	scene  *testScene
+	setup  func(*Engine, Scene)
+}
+
+func (g *testResGame) Setup() {
+	g.engine.MountFS(fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}})
+	g.engine.AddLoader(&testResLoader{})
+	g.engine.AddScene(g.scene)
+	g.setup(g.engine, g.scene)
+}
+
+func (g *testResGame) Update(delta float64) {}
+
+func newTestResEngine(t *testing.T) *Engine {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}})
+	e.AddLoader(&testResLoader{})
+
+	if _, err := e.LoadRes("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+// Runs f and returns what has been logged meanwhile.
+func captureLog(f func()) string {
+	var buffer bytes.Buffer
+	log.SetOutput(&buffer)
+	defer log.SetOutput(os.Stderr)
+	f()
+	return buffer.String()
+}
+
+func TestAcquireReleaseRes(t *testing.T) {
+	e := newTestResEngine(t)
+
+	if e.AcquireRes("missing") != nil {
+		t.Fatal("Expected missing resource not to be acquired")
+	}
+
+	res := e.AcquireRes("a.txt")
+
+	if res == nil || e.AcquireRes("a.txt") != res {
+		t.Fatal("Expected resource to be acquired")
+	}
+
+	if refs := e.GetResRefCount("a.txt"); refs != 2 {
+		t.Fatalf("Expected 2 references, got %v", refs)
+	}
+
+	if !e.ReleaseRes(res) || e.GetResByName("a.txt") == nil || e.GetResRefCount("a.txt") != 1 {
+		t.Fatal("Expected resource to be kept after first release")
+	}
+
+	if !e.ReleaseRes(res) || e.GetResByName("a.txt") != nil || e.GetResRefCount("a.txt") != 0 {
+		t.Fatal("Expected resource to be removed after last release")
+	}
+
+	if e.ReleaseRes(res) {
+		t.Fatal("Expected release of unknown resource to fail")
+	}
+}
+
+func TestReleaseResNotAcquired(t *testing.T) {
+	e := newTestResEngine(t)
+	res := e.GetResByName("a.txt")
+
+	if e.ReleaseRes(res) || e.GetResByName("a.txt") == nil {
+		t.Fatal("Expected resource not acquired to be kept")
+	}
+
+	if refs := e.GetResRefCount("a.txt"); refs != 0 {
+		t.Fatalf("Expected no references, got %v", refs)
+	}
+}
+
+func TestUnloadRes(t *testing.T) {
+	e := newTestResEngine(t)
+	res, err := e.loadRes("a.txt", "b", json.RawMessage(`{"x":1}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.AcquireRes("b")
+	e.AddReloadCallback("b", func(Res) {})
+	e.resStates[res] = fileState{}
+
+	if e.UnloadRes("missing") {
+		t.Fatal("Expected unloading missing resource to fail")
+	}
+
+	if !e.UnloadRes("b") || e.GetResByName("b") != nil || e.GetResRefCount("b") != 0 {
+		t.Fatal("Expected resource to be unloaded regardless of references")
+	}
+
+	if _, ok := e.resOptions[res]; ok {
+		t.Fatal("Expected options to be removed")
+	}
+
+	if _, ok := e.resStates[res]; ok {
+		t.Fatal("Expected hot reload state to be removed")
+	}
+
+	if _, ok := e.reloadCallbacks["b"]; ok {
+		t.Fatal("Expected reload callbacks to be removed")
+	}
+
+	if e.GetResByName("a.txt") == nil {
+		t.Fatal("Expected other resources to be kept")
+	}
+}
+
+func TestReportLeakedRes(t *testing.T) {
+	e := newTestResEngine(t)
+	e.AcquireRes("a.txt")
+	e.AcquireRes("a.txt")
+	output := captureLog(e.reportLeakedRes)
+
+	if !strings.Contains(output, "resource a.txt leaked, it was not released 2 times") {
+		t.Fatalf("Expected leaked resource to be reported, got: %v", output)
+	}
+}
+
+func TestRunHeadlessReportsLeakedRes(t *testing.T) {
+	game := &testResGame{engine: NewEngine(), scene: &testScene{name: "level"}}
+	game.setup = func(e *Engine, scene Scene) {
+		if _, err := e.LoadRes("a.txt"); err != nil {
+			t.Fatal(err)
+		}
+
+		e.AcquireRes("a.txt")
+	}
+	output := captureLog(func() { game.engine.RunHeadless(game, 1, 0.1) })
+
+	if !strings.Contains(output, "resource a.txt leaked") {
+		t.Fatalf("Expected acquired resource to be reported on cleanup, got: %v", output)
+	}
+}
+
+func TestRunHeadlessReleasesSceneRes(t *testing.T) {
+	game := &testResGame{engine: NewEngine(), scene: &testScene{name: "level"}}
+	game.setup = func(e *Engine, scene Scene) {
+		if _, err := e.LoadSceneRes(scene, "a.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	output := captureLog(func() { game.engine.RunHeadless(game, 1, 0.1) })
+
+	if !strings.Contains(output, "Unloaded resource: a.txt") {
+		t.Fatalf("Expected scene resource to be released on cleanup, got: %v", output)
+	}
+
+	if strings.Contains(output, "leaked") {
+		t.Fatalf("Expected scene resource not to be reported as leaked, got: %v", output)
+	}
+}
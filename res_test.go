@@ -0,0 +1,36 @@
+package goga
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+// A loader not implementing ConfigurableResLoader.
+type testPlainResLoader struct {
+	loader testResLoader
+}
+
+func (l *testPlainResLoader) Load(r io.Reader) (Res, error) {
+	return l.loader.Load(r)
+}
+
+func (l *testPlainResLoader) Ext() string {
+	return "txt"
+}
+
+func TestLoadResIgnoresOptions(t *testing.T) {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}})
+	e.AddLoader(&testPlainResLoader{})
+	res, err := e.loadRes("a.txt", "a", json.RawMessage(`{"filter":"nearest"}`))
+
+	if err != nil {
+		t.Fatalf("Expected options to be ignored, got %v", err)
+	}
+
+	if loaded := res.(*testRes); loaded.content != "a" || loaded.options != "" {
+		t.Fatalf("Expected resource to be loaded without options, got %v", *loaded)
+	}
+}
@@ -7,7 +7,13 @@ import (
 // Finds and returns a Tex resource.
 // If not found or when the resource is of wrong type, an error will be returned.
 func GetTex(name string) (*Tex, error) {
-	res := GetResByName(name)
+	return defaultEngine.GetTex(name)
+}
+
+// Finds and returns a Tex resource.
+// If not found or when the resource is of wrong type, an error will be returned.
+func (e *Engine) GetTex(name string) (*Tex, error) {
+	res := e.GetResByName(name)
 
 	if res == nil {
 		return nil, errors.New("Resource not found")
@@ -25,7 +31,13 @@ func GetTex(name string) (*Tex, error) {
 // Finds and returns a Ply resource.
 // If not found or when the resource is of wrong type, an error will be returned.
 func GetPly(name string) (*Ply, error) {
-	res := GetResByName(name)
+	return defaultEngine.GetPly(name)
+}
+
+// Finds and returns a Ply resource.
+// If not found or when the resource is of wrong type, an error will be returned.
+func (e *Engine) GetPly(name string) (*Ply, error) {
+	res := e.GetResByName(name)
 
 	if res == nil {
 		return nil, errors.New("Resource not found")
@@ -39,3 +51,79 @@ func GetPly(name string) (*Ply, error) {
 
 	return ply, nil
 }
+
+// Finds and returns an Obj resource and resolves its materials from loaded mtl and texture resources.
+// If not found or when the resource is of wrong type, an error will be returned.
+func GetObj(name string) (*Obj, error) {
+	return defaultEngine.GetObj(name)
+}
+
+// Finds and returns an Obj resource and resolves its materials from loaded mtl and texture resources.
+// If not found or when the resource is of wrong type, an error will be returned.
+func (e *Engine) GetObj(name string) (*Obj, error) {
+	res := e.GetResByName(name)
+
+	if res == nil {
+		return nil, errors.New("Resource not found")
+	}
+
+	obj, ok := res.(*Obj)
+
+	if !ok {
+		return nil, errors.New("Resource was not of type *Obj")
+	}
+
+	e.resolveObjMaterials(obj)
+
+	return obj, nil
+}
+
+// Finds and returns a Mtl resource.
+// If not found or when the resource is of wrong type, an error will be returned.
+func GetMtl(name string) (*Mtl, error) {
+	return defaultEngine.GetMtl(name)
+}
+
+// Finds and returns a Mtl resource.
+// If not found or when the resource is of wrong type, an error will be returned.
+func (e *Engine) GetMtl(name string) (*Mtl, error) {
+	res := e.GetResByName(name)
+
+	if res == nil {
+		return nil, errors.New("Resource not found")
+	}
+
+	mtl, ok := res.(*Mtl)
+
+	if !ok {
+		return nil, errors.New("Resource was not of type *Mtl")
+	}
+
+	return mtl, nil
+}
+
+// Finds and returns a Gltf resource and resolves its external images from loaded texture resources.
+// If not found or when the resource is of wrong type, an error will be returned.
+func GetGltf(name string) (*Gltf, error) {
+	return defaultEngine.GetGltf(name)
+}
+
+// Finds and returns a Gltf resource and resolves its external images from loaded texture resources.
+// If not found or when the resource is of wrong type, an error will be returned.
+func (e *Engine) GetGltf(name string) (*Gltf, error) {
+	res := e.GetResByName(name)
+
+	if res == nil {
+		return nil, errors.New("Resource not found")
+	}
+
+	gltf, ok := res.(*Gltf)
+
+	if !ok {
+		return nil, errors.New("Resource was not of type *Gltf")
+	}
+
+	e.resolveGltfImages(gltf)
+
+	return gltf, nil
+}
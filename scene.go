@@ -4,11 +4,6 @@ import (
 	"log"
 )
 
-var (
-	scenes      []Scene
-	activeScene Scene
-)
-
 // A scene used to switch between game states.
 // The Cleanup() method is called when a scene is removed
 // or the program is stopped. It can be used to cleanup open resources
@@ -16,6 +11,9 @@ var (
 // On switch, Pause() and Resume() are called.
 // The name returned by GetName() must be unique. A scene must only be
 // registered once.
+// Scenes are organized on a stack, the scene on top is the active scene.
+// Pause() is called when a scene stops being updated, Resume() when it is updated again.
+// Resize() is called for all scenes on the stack.
 type Scene interface {
 	Pause()
 	Resume()
@@ -24,21 +22,59 @@ type Scene interface {
 	GetName() string
 }
 
+// Optional interface for scenes laid over other scenes (like a pause menu or dialog).
+// By default, scenes below the active scene are neither updated nor drawn.
+// UpdateBelow() and DrawBelow() tell if the scene below should keep updating and drawing.
+type OverlayScene interface {
+	Scene
+	UpdateBelow() bool
+	DrawBelow() bool
+}
+
+// Optional interface for scenes to be updated each frame,
+// as long as they are updated (see OverlayScene).
+type UpdatableScene interface {
+	Scene
+	Update(float64)
+}
+
+// Optional interface for scenes to draw each frame after render systems,
+// as long as they are drawn (see OverlayScene).
+// Scenes are drawn from bottom to top of the stack.
+type DrawableScene interface {
+	Scene
+	Draw(float64)
+}
+
+// Systems, actors and resources owned by a scene.
+type sceneData struct {
+	systems   []systemEntry
+	actors    []*Actor
+	resources []Res
+}
+
 // Adds a scene to game.
 // Returns false if the scene exists already.
 // The first scene added will be set active.
 func AddScene(scene Scene) bool {
-	for _, s := range scenes {
+	return defaultEngine.AddScene(scene)
+}
+
+// Adds a scene to the engine.
+// Returns false if the scene exists already.
+// The first scene added will be set active.
+func (e *Engine) AddScene(scene Scene) bool {
+	for _, s := range e.scenes {
 		if s == scene {
 			return false
 		}
 	}
 
-	scenes = append(scenes, scene)
+	e.scenes = append(e.scenes, scene)
 	log.Print("Added scene: " + scene.GetName())
 
-	if activeScene == nil {
-		activeScene = scene
+	if len(e.sceneStack) == 0 {
+		e.sceneStack = append(e.sceneStack, scene)
 		log.Print("Active scene: " + scene.GetName())
 	}
 
@@ -46,12 +82,22 @@ func AddScene(scene Scene) bool {
 }
 
 // Removes a given scene.
+// Systems, actors and resources owned by the scene are removed and cleaned up.
 // Returns false if it could not be found.
 func RemoveScene(scene Scene) bool {
-	for i, s := range scenes {
+	return defaultEngine.RemoveScene(scene)
+}
+
+// Removes a given scene.
+// Systems, actors and resources owned by the scene are removed and cleaned up.
+// Returns false if it could not be found.
+func (e *Engine) RemoveScene(scene Scene) bool {
+	for i, s := range e.scenes {
 		if s == scene {
+			e.setSceneStack(removeScene(e.sceneStack, scene))
+			e.cleanupSceneData(s)
 			s.Cleanup()
-			scenes = append(scenes[:i], scenes[i+1:]...)
+			e.scenes = append(e.scenes[:i], e.scenes[i+1:]...)
 			log.Print("Removed scene: " + scene.GetName())
 			return true
 		}
@@ -62,17 +108,29 @@ func RemoveScene(scene Scene) bool {
 
 // Removes all scenes.
 func RemoveAllScenes() {
-	for _, s := range scenes {
+	defaultEngine.RemoveAllScenes()
+}
+
+// Removes all scenes.
+func (e *Engine) RemoveAllScenes() {
+	for _, s := range e.scenes {
+		e.cleanupSceneData(s)
 		s.Cleanup()
 	}
 
-	scenes = make([]Scene, 0)
+	e.scenes = make([]Scene, 0)
+	e.sceneStack = make([]Scene, 0)
 	log.Print("Cleared scenes")
 }
 
 // Finds and returns a scene by name, or nil if not found.
 func GetSceneByName(name string) Scene {
-	for _, s := range scenes {
+	return defaultEngine.GetSceneByName(name)
+}
+
+// Finds and returns a scene by name, or nil if not found.
+func (e *Engine) GetSceneByName(name string) Scene {
+	for _, s := range e.scenes {
 		if s.GetName() == name {
 			return s
 		}
@@ -81,40 +139,458 @@ func GetSceneByName(name string) Scene {
 	return nil
 }
 
-// Switches to given scene.
+// Switches to given scene by replacing the active scene on top of the stack.
 // This will pause the currently active scene.
 func SwitchScene(scene Scene) {
-	if activeScene != nil {
-		activeScene.Pause()
+	defaultEngine.SwitchScene(scene)
+}
+
+// Switches to given scene by replacing the active scene on top of the stack.
+// This will pause the currently active scene.
+func (e *Engine) SwitchScene(scene Scene) {
+	if e.GetActiveScene() == scene {
+		return
 	}
 
-	activeScene = scene
-	log.Print("Active scene: " + scene.GetName())
+	stack := removeScene(e.sceneStack, scene)
 
-	for _, s := range scenes {
-		if s == activeScene {
-			activeScene.Resume()
-			break
-		}
+	if len(stack) > 0 {
+		stack = stack[:len(stack)-1]
 	}
+
+	e.setSceneStack(append(stack, scene))
+	log.Print("Active scene: " + scene.GetName())
 }
 
 // Switches to given existing scene by name.
 // Returns false if the scene does not exist.
 func SwitchSceneByName(name string) bool {
-	scene := GetSceneByName(name)
+	return defaultEngine.SwitchSceneByName(name)
+}
+
+// Switches to given existing scene by name.
+// Returns false if the scene does not exist.
+func (e *Engine) SwitchSceneByName(name string) bool {
+	scene := e.GetSceneByName(name)
 
 	if scene == nil {
 		return false
 	}
 
-	SwitchScene(scene)
+	e.SwitchScene(scene)
 
 	return true
 }
 
-// Returns the currently active scene.
+// Pushes given scene on top of the scene stack, making it the active scene.
+// Scenes below will be paused, unless the new scene lets them update (see OverlayScene).
+// Returns false if the scene is on the stack already.
+func PushScene(scene Scene) bool {
+	return defaultEngine.PushScene(scene)
+}
+
+// Pushes given scene on top of the scene stack, making it the active scene.
+// See PushScene() for details.
+func (e *Engine) PushScene(scene Scene) bool {
+	if containsScene(e.sceneStack, scene) {
+		return false
+	}
+
+	stack := make([]Scene, len(e.sceneStack), len(e.sceneStack)+1)
+	copy(stack, e.sceneStack)
+	e.setSceneStack(append(stack, scene))
+	log.Print("Active scene: " + scene.GetName())
+
+	return true
+}
+
+// Pushes given existing scene by name on top of the scene stack.
+// Returns false if the scene does not exist or is on the stack already.
+func PushSceneByName(name string) bool {
+	return defaultEngine.PushSceneByName(name)
+}
+
+// Pushes given existing scene by name on top of the scene stack.
+// Returns false if the scene does not exist or is on the stack already.
+func (e *Engine) PushSceneByName(name string) bool {
+	scene := e.GetSceneByName(name)
+
+	if scene == nil {
+		return false
+	}
+
+	return e.PushScene(scene)
+}
+
+// Removes the active scene from top of the scene stack and returns it.
+// The scene below becomes active and will be resumed.
+// Returns nil if the stack is empty.
+func PopScene() Scene {
+	return defaultEngine.PopScene()
+}
+
+// Removes the active scene from top of the scene stack and returns it.
+// See PopScene() for details.
+func (e *Engine) PopScene() Scene {
+	if len(e.sceneStack) == 0 {
+		return nil
+	}
+
+	scene := e.sceneStack[len(e.sceneStack)-1]
+	e.setSceneStack(e.sceneStack[:len(e.sceneStack)-1])
+
+	if active := e.GetActiveScene(); active != nil {
+		log.Print("Active scene: " + active.GetName())
+	}
+
+	return scene
+}
+
+// Returns the currently active scene, which is the top of the scene stack.
 // Can be nil if no scene was set.
 func GetActiveScene() Scene {
-	return activeScene
+	return defaultEngine.GetActiveScene()
+}
+
+// Returns the currently active scene, which is the top of the scene stack.
+// Can be nil if no scene was set.
+func (e *Engine) GetActiveScene() Scene {
+	if len(e.sceneStack) == 0 {
+		return nil
+	}
+
+	return e.sceneStack[len(e.sceneStack)-1]
+}
+
+// Returns a copy of the scene stack, ordered from bottom to top.
+func GetSceneStack() []Scene {
+	return defaultEngine.GetSceneStack()
+}
+
+// Returns a copy of the scene stack, ordered from bottom to top.
+func (e *Engine) GetSceneStack() []Scene {
+	stack := make([]Scene, len(e.sceneStack))
+	copy(stack, e.sceneStack)
+
+	return stack
+}
+
+// Sets the scene stack and pauses/resumes scenes which stopped/started being updated.
+func (e *Engine) setSceneStack(stack []Scene) {
+	before := updatedScenes(e.sceneStack)
+	e.sceneStack = stack
+	after := updatedScenes(e.sceneStack)
+
+	for _, scene := range before {
+		if !containsScene(after, scene) {
+			scene.Pause()
+		}
+	}
+
+	for _, scene := range after {
+		if !containsScene(before, scene) {
+			scene.Resume()
+		}
+	}
+}
+
+// Returns the scenes of the stack which are updated, from bottom to top.
+func updatedScenes(stack []Scene) []Scene {
+	if len(stack) == 0 {
+		return stack
+	}
+
+	i := len(stack) - 1
+
+	for i > 0 {
+		overlay, ok := stack[i].(OverlayScene)
+
+		if !ok || !overlay.UpdateBelow() {
+			break
+		}
+
+		i--
+	}
+
+	return stack[i:]
+}
+
+// Returns the scenes of the stack which are drawn, from bottom to top.
+func drawnScenes(stack []Scene) []Scene {
+	if len(stack) == 0 {
+		return stack
+	}
+
+	i := len(stack) - 1
+
+	for i > 0 {
+		overlay, ok := stack[i].(OverlayScene)
+
+		if !ok || !overlay.DrawBelow() {
+			break
+		}
+
+		i--
+	}
+
+	return stack[i:]
+}
+
+func (e *Engine) updateScenes(delta float64) {
+	for _, scene := range updatedScenes(e.sceneStack) {
+		if updatable, ok := scene.(UpdatableScene); ok {
+			updatable.Update(delta)
+		}
+	}
+}
+
+func drawScenes(stack []Scene, delta float64) {
+	for _, scene := range drawnScenes(stack) {
+		if drawable, ok := scene.(DrawableScene); ok {
+			drawable.Draw(delta)
+		}
+	}
+}
+
+// Passes the new window size to all scenes on the stack.
+func (e *Engine) resizeScenes(width, height int) {
+	for _, scene := range e.sceneStack {
+		scene.Resize(width, height)
+	}
+}
+
+func containsScene(scenes []Scene, scene Scene) bool {
+	for _, s := range scenes {
+		if s == scene {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns a new slice without given scene.
+func removeScene(scenes []Scene, scene Scene) []Scene {
+	result := make([]Scene, 0, len(scenes))
+
+	for _, s := range scenes {
+		if s != scene {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+func (e *Engine) getSceneData(scene Scene) *sceneData {
+	data := e.sceneData[scene]
+
+	if data == nil {
+		data = &sceneData{}
+		data.systems = make([]systemEntry, 0)
+		data.actors = make([]*Actor, 0)
+		data.resources = make([]Res, 0)
+		e.sceneData[scene] = data
+	}
+
+	return data
+}
+
+// Removes systems, actors and resources owned by given scene.
+func (e *Engine) cleanupSceneData(scene Scene) {
+	data := e.sceneData[scene]
+
+	if data == nil {
+		return
+	}
+
+	delete(e.sceneData, scene)
+
+	for _, entry := range data.systems {
+		entry.system.Cleanup()
+	}
+
+	for _, actor := range data.actors {
+		e.DestroyActor(actor)
+	}
+
+	for _, res := range data.resources {
+		e.ReleaseRes(res)
+	}
+
+	log.Printf("Cleaned up %v systems, %v actors and %v resources of scene: %v", len(data.systems), len(data.actors), len(data.resources), scene.GetName())
+}
+
+// Adds a system owned by given scene using the default priority.
+// The system is only updated while the scene is updated (simulation systems)
+// or drawn (render systems). It is cleaned up when the scene is removed.
+// Returns false if the scene owns the system already.
+func AddSceneSystem(scene Scene, system System) bool {
+	return defaultEngine.AddSceneSystem(scene, system)
+}
+
+// Adds a system owned by given scene using the default priority.
+// See AddSceneSystem() for details.
+func (e *Engine) AddSceneSystem(scene Scene, system System) bool {
+	return e.AddSceneSystemWithPriority(scene, system, Default_system_priority)
+}
+
+// Adds a system owned by given scene with given priority.
+// Scene systems are ordered together with global systems by priority.
+// Returns false if the scene owns the system already.
+func AddSceneSystemWithPriority(scene Scene, system System, priority int) bool {
+	return defaultEngine.AddSceneSystemWithPriority(scene, system, priority)
+}
+
+// Adds a system owned by given scene with given priority.
+// See AddSceneSystemWithPriority() for details.
+func (e *Engine) AddSceneSystemWithPriority(scene Scene, system System, priority int) bool {
+	data := e.getSceneData(scene)
+
+	for _, entry := range data.systems {
+		if entry.system == system {
+			return false
+		}
+	}
+
+	data.systems = insertSystem(data.systems, systemEntry{system, priority, true})
+	e.addMatchingActors(system)
+
+	return true
+}
+
+// Removes a system owned by given scene and cleans it up.
+// Returns false if it could not be found.
+func RemoveSceneSystem(scene Scene, system System) bool {
+	return defaultEngine.RemoveSceneSystem(scene, system)
+}
+
+// Removes a system owned by given scene and cleans it up.
+// Returns false if it could not be found.
+func (e *Engine) RemoveSceneSystem(scene Scene, system System) bool {
+	data := e.sceneData[scene]
+
+	if data == nil {
+		return false
+	}
+
+	for i, entry := range data.systems {
+		if entry.system == system {
+			system.Cleanup()
+			data.systems = append(data.systems[:i], data.systems[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Finds and returns a system owned by given scene by name, or nil if not found.
+func GetSceneSystemByName(scene Scene, name string) System {
+	return defaultEngine.GetSceneSystemByName(scene, name)
+}
+
+// Finds and returns a system owned by given scene by name, or nil if not found.
+func (e *Engine) GetSceneSystemByName(scene Scene, name string) System {
+	data := e.sceneData[scene]
+
+	if data == nil {
+		return nil
+	}
+
+	for _, entry := range data.systems {
+		if entry.system.GetName() == name {
+			return entry.system
+		}
+	}
+
+	return nil
+}
+
+// Makes given scene owner of an actor.
+// When the scene is removed, the actor will be destroyed (see DestroyActor()).
+// Returns false if the scene owns the actor already.
+func AddSceneActor(scene Scene, actor *Actor) bool {
+	return defaultEngine.AddSceneActor(scene, actor)
+}
+
+// Makes given scene owner of an actor.
+// See AddSceneActor() for details.
+func (e *Engine) AddSceneActor(scene Scene, actor *Actor) bool {
+	data := e.getSceneData(scene)
+	id := actor.GetId()
+
+	for _, a := range data.actors {
+		if a.GetId() == id {
+			return false
+		}
+	}
+
+	data.actors = append(data.actors, actor)
+
+	return true
+}
+
+// Removes the ownership of an actor from given scene.
+// The actor itself is not removed from systems.
+// Returns false if the scene does not own the actor.
+func RemoveSceneActor(scene Scene, actor *Actor) bool {
+	return defaultEngine.RemoveSceneActor(scene, actor)
+}
+
+// Removes the ownership of an actor from given scene.
+// See RemoveSceneActor() for details.
+func (e *Engine) RemoveSceneActor(scene Scene, actor *Actor) bool {
+	data := e.sceneData[scene]
+
+	if data == nil {
+		return false
+	}
+
+	id := actor.GetId()
+
+	for i, a := range data.actors {
+		if a.GetId() == id {
+			data.actors = append(data.actors[:i], data.actors[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Loads a resource owned by given scene.
+// The resource is available like any other resource and acquired by the scene.
+// It will be released when the scene is removed, so it's dropped if no one else acquired it.
+// If the resource was loaded by another scene, it is acquired instead of loaded again,
+// so scenes can share resources. Resources loaded using LoadRes() are returned as they are.
+// See LoadRes() for details.
+func LoadSceneRes(scene Scene, path string) (Res, error) {
+	return defaultEngine.LoadSceneRes(scene, path)
+}
+
+// Loads a resource owned by given scene.
+// See LoadSceneRes() for details.
+func (e *Engine) LoadSceneRes(scene Scene, path string) (Res, error) {
+	res := e.GetResByPath(path)
+
+	if res != nil && e.resRefs[res] == 0 {
+		// loaded globally, so it's not owned by scenes
+		return res, nil
+	}
+
+	if res == nil {
+		var err error
+		res, err = e.LoadRes(path)
+
+		if err != nil {
+			return res, err
+		}
+	}
+
+	e.AcquireRes(res.GetName())
+	data := e.getSceneData(scene)
+	data.resources = append(data.resources, res)
+
+	return res, nil
 }
@@ -0,0 +1,206 @@
+package goga
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+type testOverlayScene struct {
+	testScene
+	updateBelow, drawBelow bool
+}
+
+func (s *testOverlayScene) UpdateBelow() bool { return s.updateBelow }
+func (s *testOverlayScene) DrawBelow() bool   { return s.drawBelow }
+
+func TestRemoveSceneDestroysActors(t *testing.T) {
+	e := NewEngine()
+	scene := &testScene{name: "level"}
+	e.AddScene(scene)
+	parent := NewActor()
+	child := NewActor()
+	destroyed := 0
+
+	e.SetActorName(parent, "player")
+	e.AddActorTag(parent, "friendly")
+	e.SetParent(child, parent, false)
+	e.AddDestroyCallback(parent, func(*Actor) { destroyed++ })
+	e.AddDestroyCallback(child, func(*Actor) { destroyed++ })
+	e.AddSceneActor(scene, parent)
+	e.RemoveScene(scene)
+
+	if destroyed != 2 {
+		t.Fatalf("Expected parent and child to be destroyed, got %v destroy callbacks", destroyed)
+	}
+
+	if e.FindActorByName("player") != nil || len(e.FindActorsByTag("friendly")) != 0 {
+		t.Fatal("Expected actor name and tag to be removed")
+	}
+
+	if e.GetParent(child.GetId()) != nil || len(e.GetChildren(parent.GetId())) != 0 {
+		t.Fatal("Expected actors to be removed from hierarchy")
+	}
+}
+
+type testDrawScene struct {
+	testScene
+	draws int
+}
+
+func (s *testDrawScene) Draw(float64) { s.draws++ }
+
+// A render system counting its updates.
+type testRenderSystem struct {
+	testSystem
+}
+
+func (s *testRenderSystem) IsRenderSystem() bool { return true }
+
+func TestSceneStack(t *testing.T) {
+	e := NewEngine()
+	menu, level, pause := &testScene{name: "menu"}, &testScene{name: "level"}, &testScene{name: "pause"}
+	e.AddScene(menu)
+	e.AddScene(level)
+	e.AddScene(pause)
+
+	if e.GetActiveScene() != menu {
+		t.Fatal("Expected first scene to be active")
+	}
+
+	e.SwitchScene(level)
+
+	if stack := e.GetSceneStack(); len(stack) != 1 || stack[0] != level || menu.paused != 1 || level.resumed != 1 {
+		t.Fatalf("Expected switch to replace the active scene, got %v", stack)
+	}
+
+	if !e.PushScene(pause) || e.PushScene(pause) || e.GetActiveScene() != pause || level.paused != 1 {
+		t.Fatal("Expected scene to be pushed once and the scene below to be paused")
+	}
+
+	if e.PopScene() != pause || e.GetActiveScene() != level || level.resumed != 2 || pause.paused != 1 {
+		t.Fatal("Expected scene below to be resumed on pop")
+	}
+
+	if e.PopScene() != level || e.GetActiveScene() != nil || e.PopScene() != nil {
+		t.Fatal("Expected stack to be empty")
+	}
+
+	if !e.PushSceneByName("menu") || e.PushSceneByName("missing") || e.GetActiveScene() != menu {
+		t.Fatal("Expected scene to be pushed by name")
+	}
+}
+
+func TestOverlayScene(t *testing.T) {
+	tests := []struct {
+		updateBelow, drawBelow bool
+		updates, draws         int
+	}{
+		{false, false, 0, 0},
+		{true, false, 1, 0},
+		{false, true, 0, 1},
+		{true, true, 1, 1},
+	}
+
+	for _, test := range tests {
+		e := NewEngine()
+		level := &testDrawScene{testScene: testScene{name: "level"}}
+		overlay := &testOverlayScene{testScene{name: "overlay"}, test.updateBelow, test.drawBelow}
+		e.AddScene(level)
+		e.AddScene(overlay)
+		e.PushScene(overlay)
+		e.updateScenes(0)
+		drawScenes(e.sceneStack, 0)
+
+		if level.updates != test.updates || level.draws != test.draws || overlay.updates != 1 {
+			t.Fatalf("UpdateBelow %v, DrawBelow %v: expected %v updates and %v draws, got %v and %v",
+				test.updateBelow, test.drawBelow, test.updates, test.draws, level.updates, level.draws)
+		}
+
+		if (level.paused == 0) != test.updateBelow {
+			t.Fatalf("UpdateBelow %v: expected scene below to be paused if not updated", test.updateBelow)
+		}
+	}
+}
+
+func TestSceneSystems(t *testing.T) {
+	e := NewEngine()
+	level := &testScene{name: "level"}
+	overlay := &testOverlayScene{testScene{name: "overlay"}, false, true}
+	e.AddScene(level)
+	e.AddScene(overlay)
+	simulation, render := &testSystem{}, &testRenderSystem{}
+	e.AddSceneSystem(level, simulation)
+	e.AddSceneSystem(level, render)
+
+	if e.AddSceneSystem(level, simulation) || e.GetSceneSystemByName(level, "testSystem") == nil {
+		t.Fatal("Expected scene system to be added once")
+	}
+
+	e.updateSimulationSystems(0)
+	e.updateRenderSystems(e.sceneStack, 0)
+
+	if simulation.updates != 1 || render.updates != 1 {
+		t.Fatal("Expected systems of active scene to be updated")
+	}
+
+	// the overlay draws the level below, but pauses it
+	e.PushScene(overlay)
+	e.updateSimulationSystems(0)
+	e.updateRenderSystems(e.sceneStack, 0)
+
+	if simulation.updates != 1 || render.updates != 2 {
+		t.Fatalf("Expected only render system of drawn scene to be updated, got %v and %v", simulation.updates, render.updates)
+	}
+
+	e.PopScene()
+	e.SwitchScene(overlay)
+	e.updateSimulationSystems(0)
+	e.updateRenderSystems(e.sceneStack, 0)
+
+	if simulation.updates != 1 || render.updates != 2 {
+		t.Fatal("Expected systems of inactive scene not to be updated")
+	}
+
+	e.RemoveScene(level)
+
+	if !simulation.removed || !render.removed {
+		t.Fatal("Expected scene systems to be cleaned up with the scene")
+	}
+}
+
+func TestLoadSceneRes(t *testing.T) {
+	e := newTestResEngine(t)
+	e.MountFS(fstest.MapFS{"b.txt": &fstest.MapFile{Data: []byte("b")}})
+	menu, level := &testScene{name: "menu"}, &testScene{name: "level"}
+	e.AddScene(menu)
+	e.AddScene(level)
+	shared, err := e.LoadSceneRes(menu, "b.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res, _ := e.LoadSceneRes(level, "b.txt"); res != shared || e.GetResRefCount("b.txt") != 2 {
+		t.Fatal("Expected resource to be shared by scenes")
+	}
+
+	if res, _ := e.LoadSceneRes(level, "a.txt"); res == nil || e.GetResRefCount("a.txt") != 0 {
+		t.Fatal("Expected global resource not to be acquired")
+	}
+
+	e.RemoveScene(menu)
+
+	if e.GetResByName("b.txt") != shared || e.GetResRefCount("b.txt") != 1 {
+		t.Fatal("Expected resource to be kept while another scene uses it")
+	}
+
+	e.RemoveScene(level)
+
+	if e.GetResByName("b.txt") != nil || e.GetResByName("a.txt") == nil {
+		t.Fatal("Expected scene resource to be released and global resource to be kept")
+	}
+
+	if _, err := e.LoadSceneRes(menu, "missing.txt"); err == nil {
+		t.Fatal("Expected error for missing resource")
+	}
+}
@@ -39,6 +39,7 @@ type SpriteRenderer struct {
 	Camera *Camera
 
 	sprites                 []Sprite
+	entries                 tombstoneIndex
 	index, vertex, texCoord *VBO
 	vao                     *VAO
 }
@@ -58,6 +59,7 @@ func NewSpriteRenderer(shader *Shader, camera *Camera, flip bool) *SpriteRendere
 	renderer.Shader = shader
 	renderer.Camera = camera
 	renderer.sprites = make([]Sprite, 0)
+	renderer.entries = newTombstoneIndex()
 	renderer.index, renderer.vertex, renderer.texCoord = CreateRectMesh(flip)
 	renderer.Size = Vec2{1, 1}
 	renderer.Scale = Vec2{1, 1}
@@ -90,10 +92,8 @@ func (s *SpriteRenderer) Cleanup() {
 func (s *SpriteRenderer) Add(actor *Actor, pos *Pos2D, tex *Tex) bool {
 	id := actor.GetId()
 
-	for _, sprite := range s.sprites {
-		if id == sprite.Actor.GetId() {
-			return false
-		}
+	if !s.entries.add(id, len(s.sprites)) {
+		return false
 	}
 
 	s.sprites = append(s.sprites, Sprite{actor, pos, tex})
@@ -101,6 +101,17 @@ func (s *SpriteRenderer) Add(actor *Actor, pos *Pos2D, tex *Tex) bool {
 	return true
 }
 
+// Returns the query for actors rendered as sprites.
+// Actors having a KeyframeSet are rendered by the KeyframeRenderer instead.
+func (s *SpriteRenderer) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos2DType, TexType}, Exclude: []ComponentType{KeyframeSetType}}
+}
+
+// Adds sprite to the renderer from component store.
+func (s *SpriteRenderer) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	return s.Add(actor, store.GetPos2D(actor.GetId()), store.GetTex(actor.GetId()))
+}
+
 // Removes sprite from renderer.
 func (s *SpriteRenderer) Remove(actor *Actor) bool {
 	return s.RemoveById(actor.GetId())
@@ -108,24 +119,51 @@ func (s *SpriteRenderer) Remove(actor *Actor) bool {
 
 // Removes sprite from renderer by ID.
 func (s *SpriteRenderer) RemoveById(id ActorId) bool {
-	for i, sprite := range s.sprites {
-		if sprite.Actor.GetId() == id {
-			s.sprites = append(s.sprites[:i], s.sprites[i+1:]...)
-			return true
-		}
+	i, ok := s.entries.remove(id)
+
+	if !ok {
+		return false
+	}
+
+	// keep render order, removed entries are compacted on next update
+	// or as soon as more than half of them are removed
+	s.sprites[i] = Sprite{}
+
+	if s.entries.mostlyRemoved(len(s.sprites)) {
+		s.compact()
 	}
 
-	return false
+	return true
 }
 
 // Removes all sprites from renderer.
 func (s *SpriteRenderer) RemoveAll() {
 	s.sprites = make([]Sprite, 0)
+	s.entries.reset()
 }
 
 // Returns number of sprites.
 func (s *SpriteRenderer) Len() int {
-	return len(s.sprites)
+	return s.entries.len(len(s.sprites))
+}
+
+// Returns true if the sprite with given actor ID was added to the renderer.
+func (s *SpriteRenderer) Has(id ActorId) bool {
+	return s.entries.has(id)
+}
+
+// Removes entries left by RemoveById() and updates indices, keeping the order.
+func (s *SpriteRenderer) compact() {
+	n := s.entries.compact(len(s.sprites),
+		func(i int) *Actor { return s.sprites[i].Actor },
+		func(from, to int) { s.sprites[to] = s.sprites[from] },
+		func(i int) { s.sprites[i] = Sprite{} })
+	s.sprites = s.sprites[:n]
+}
+
+// Returns true, as this is a render system.
+func (s *SpriteRenderer) IsRenderSystem() bool {
+	return true
 }
 
 func (s *SpriteRenderer) GetName() string {
@@ -134,6 +172,8 @@ func (s *SpriteRenderer) GetName() string {
 
 // Render sprites.
 func (s *SpriteRenderer) Update(delta float64) {
+	s.compact()
+
 	s.Shader.Bind()
 	s.Shader.SendMat3(Default_shader_2D_ortho, *MultMat3(s.Camera.CalcOrtho(), s.CalcModel()))
 	s.Shader.SendUniform1i(Default_shader_2D_tex, 0)
@@ -141,11 +181,11 @@ func (s *SpriteRenderer) Update(delta float64) {
 	var tid uint32
 
 	for _, sprite := range s.sprites {
-		if !sprite.Visible {
+		if sprite.Actor == nil || !sprite.Visible {
 			continue
 		}
 
-		s.Shader.SendMat3(Default_shader_2D_model, *sprite.CalcModel())
+		s.Shader.SendMat3(Default_shader_2D_model, *sprite.CalcWorld())
 
 		// prevent texture switching when not neccessary
 		if tid != sprite.Tex.GetId() {
@@ -1,9 +1,11 @@
 package goga
 
-import ()
-
-var (
-	systems []System
+const (
+	// Priorities used for default systems.
+	// Systems with lower priority are updated first.
+	Default_system_priority = 0
+	Culling_system_priority = 100
+	Render_system_priority  = 200
 )
 
 // A system provides logic for actors satisfying required components.
@@ -21,78 +23,396 @@ type System interface {
 	GetName() string
 }
 
-// Adds a system to the game.
+// A render system is a system drawing actors.
+// Systems not implementing this interface (or returning false) are simulation systems.
+// When running with a fixed time step, simulation systems are updated in fixed steps,
+// while render systems are updated once per frame after the simulation has been stepped.
+// Render systems can use GetAlpha() to interpolate between simulation steps, the built-in ones don't.
+type RenderSystem interface {
+	System
+	IsRenderSystem() bool
+}
+
+// Systems implementing this interface are skipped by RemoveActorById(),
+// if they don't contain the actor.
+type ActorLookup interface {
+	Has(ActorId) bool
+}
+
+// A registered system together with its update priority and state.
+type systemEntry struct {
+	system   System
+	priority int
+	enabled  bool
+}
+
+// Adds a system to the game using the default priority.
 // Returns false if the system exists already.
 func AddSystem(system System) bool {
-	for _, sys := range systems {
-		if sys == system {
-			return false
-		}
+	return defaultEngine.AddSystem(system)
+}
+
+// Adds a system to the engine using the default priority.
+// Returns false if the system exists already.
+func (e *Engine) AddSystem(system System) bool {
+	return e.AddSystemWithPriority(system, Default_system_priority)
+}
+
+// Adds a system to the game with given priority.
+// Systems with lower priority are updated first.
+// Systems with equal priority are updated in the order they were added.
+// Returns false if the system exists already.
+func AddSystemWithPriority(system System, priority int) bool {
+	return defaultEngine.AddSystemWithPriority(system, priority)
+}
+
+// Adds a system to the engine with given priority.
+// See AddSystemWithPriority() for details.
+func (e *Engine) AddSystemWithPriority(system System, priority int) bool {
+	if e.findSystem(system) != -1 {
+		return false
 	}
 
-	systems = append(systems, system)
+	e.systems = insertSystem(e.systems, systemEntry{system, priority, true})
+	e.addMatchingActors(system)
 
 	return true
 }
 
+// Inserts the entry behind all systems with lower or equal priority.
+func insertSystem(systems []systemEntry, entry systemEntry) []systemEntry {
+	i := len(systems)
+
+	for i > 0 && systems[i-1].priority > entry.priority {
+		i--
+	}
+
+	systems = append(systems, systemEntry{})
+	copy(systems[i+1:], systems[i:])
+	systems[i] = entry
+
+	return systems
+}
+
+func (e *Engine) findSystem(system System) int {
+	for i, entry := range e.systems {
+		if entry.system == system {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (e *Engine) findSystemByName(name string) int {
+	for i, entry := range e.systems {
+		if entry.system.GetName() == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // Removes the given system.
 // Returns false if it could not be found.
 func RemoveSystem(system System) bool {
-	for i, sys := range systems {
-		if sys == system {
-			sys.Cleanup()
-			systems = append(systems[:i], systems[i+1:]...)
-			return true
-		}
+	return defaultEngine.RemoveSystem(system)
+}
+
+// Removes the given system.
+// Returns false if it could not be found.
+func (e *Engine) RemoveSystem(system System) bool {
+	i := e.findSystem(system)
+
+	if i == -1 {
+		return false
 	}
 
-	return false
+	system.Cleanup()
+	e.systems = append(e.systems[:i], e.systems[i+1:]...)
+
+	return true
 }
 
 // Removes all systems.
 func RemoveAllSystems() {
-	for _, system := range systems {
-		system.Cleanup()
+	defaultEngine.RemoveAllSystems()
+}
+
+// Removes all systems.
+func (e *Engine) RemoveAllSystems() {
+	for _, entry := range e.systems {
+		entry.system.Cleanup()
 	}
 
-	systems = make([]System, 0)
+	e.systems = make([]systemEntry, 0)
 }
 
 // Finds and returns a system by name, or nil if not found.
 func GetSystemByName(name string) System {
-	for _, system := range systems {
-		if system.GetName() == name {
-			return system
+	return defaultEngine.GetSystemByName(name)
+}
+
+// Finds and returns a system by name, or nil if not found.
+func (e *Engine) GetSystemByName(name string) System {
+	if i := e.findSystemByName(name); i != -1 {
+		return e.systems[i].system
+	}
+
+	return nil
+}
+
+// Sets the priority of a system by name.
+// The system will be updated after all other systems with the same priority.
+// Returns false if the system could not be found.
+func SetSystemPriority(name string, priority int) bool {
+	return defaultEngine.SetSystemPriority(name, priority)
+}
+
+// Sets the priority of a system by name.
+// See SetSystemPriority() for details.
+func (e *Engine) SetSystemPriority(name string, priority int) bool {
+	i := e.findSystemByName(name)
+
+	if i == -1 {
+		return false
+	}
+
+	entry := e.systems[i]
+	entry.priority = priority
+	e.systems = append(e.systems[:i], e.systems[i+1:]...)
+	e.systems = insertSystem(e.systems, entry)
+
+	return true
+}
+
+// Returns the priority of a system by name.
+// Returns false if the system could not be found.
+func GetSystemPriority(name string) (int, bool) {
+	return defaultEngine.GetSystemPriority(name)
+}
+
+// Returns the priority of a system by name.
+// Returns false if the system could not be found.
+func (e *Engine) GetSystemPriority(name string) (int, bool) {
+	if i := e.findSystemByName(name); i != -1 {
+		return e.systems[i].priority, true
+	}
+
+	return 0, false
+}
+
+// Moves a system by name directly in front of another system.
+// The moved system takes over the priority of the other one.
+// Returns false if one of the systems could not be found.
+func MoveSystemBefore(name, other string) bool {
+	return defaultEngine.MoveSystemBefore(name, other)
+}
+
+// Moves a system by name directly in front of another system.
+// See MoveSystemBefore() for details.
+func (e *Engine) MoveSystemBefore(name, other string) bool {
+	return e.moveSystem(name, other, 0)
+}
+
+// Moves a system by name directly behind another system.
+// The moved system takes over the priority of the other one.
+// Returns false if one of the systems could not be found.
+func MoveSystemAfter(name, other string) bool {
+	return defaultEngine.MoveSystemAfter(name, other)
+}
+
+// Moves a system by name directly behind another system.
+// See MoveSystemAfter() for details.
+func (e *Engine) MoveSystemAfter(name, other string) bool {
+	return e.moveSystem(name, other, 1)
+}
+
+func (e *Engine) moveSystem(name, other string, offset int) bool {
+	i := e.findSystemByName(name)
+
+	if i == -1 || e.findSystemByName(other) == -1 || name == other {
+		return false
+	}
+
+	entry := e.systems[i]
+	e.systems = append(e.systems[:i], e.systems[i+1:]...)
+	j := e.findSystemByName(other)
+	entry.priority = e.systems[j].priority
+	j += offset
+
+	e.systems = append(e.systems, systemEntry{})
+	copy(e.systems[j+1:], e.systems[j:])
+	e.systems[j] = entry
+
+	return true
+}
+
+// Enables or disables a system by name.
+// Global systems are looked up first, then systems owned by scenes (see AddSceneSystem()).
+// Disabled systems are not updated, but keep their actors and resources.
+// Returns false if the system could not be found.
+func SetSystemEnabled(name string, enabled bool) bool {
+	return defaultEngine.SetSystemEnabled(name, enabled)
+}
+
+// Enables or disables a system by name.
+// See SetSystemEnabled() for details.
+func (e *Engine) SetSystemEnabled(name string, enabled bool) bool {
+	entry := e.findEntryByName(name)
+
+	if entry == nil {
+		return false
+	}
+
+	entry.enabled = enabled
+
+	return true
+}
+
+// Returns true if the system with given name exists and is enabled.
+func IsSystemEnabled(name string) bool {
+	return defaultEngine.IsSystemEnabled(name)
+}
+
+// Returns true if the system with given name exists and is enabled.
+func (e *Engine) IsSystemEnabled(name string) bool {
+	entry := e.findEntryByName(name)
+	return entry != nil && entry.enabled
+}
+
+// Returns the entry of a global or scene system by name, or nil if not found.
+func (e *Engine) findEntryByName(name string) *systemEntry {
+	if i := e.findSystemByName(name); i != -1 {
+		return &e.systems[i]
+	}
+
+	for _, scene := range e.scenes {
+		if data := e.sceneData[scene]; data != nil {
+			for i := range data.systems {
+				if data.systems[i].system.GetName() == name {
+					return &data.systems[i]
+				}
+			}
 		}
 	}
 
 	return nil
 }
 
-func updateSystems(delta float64) {
-	for _, system := range systems {
-		system.Update(delta)
+// Returns all enabled systems to update this frame for given scene stack, ordered by priority.
+// Systems owned by scenes are included if the scene is updated (simulation systems)
+// or drawn (render systems). On equal priority, global systems come first.
+// Global and scene systems are kept in order, so they are merged instead of sorted.
+// The returned slice is reused by the next call, so it must not be kept.
+func (e *Engine) activeSystems(stack []Scene) []systemEntry {
+	active := e.activeBuffer[:0]
+
+	for _, entry := range e.systems {
+		if entry.enabled {
+			active = append(active, entry)
+		}
+	}
+
+	updated := updatedScenes(stack)
+	drawn := drawnScenes(stack)
+
+	for _, scene := range stack {
+		data := e.sceneData[scene]
+
+		if data == nil {
+			continue
+		}
+
+		isUpdated := containsScene(updated, scene)
+		isDrawn := containsScene(drawn, scene)
+
+		for _, entry := range data.systems {
+			if !entry.enabled {
+				continue
+			}
+
+			if isRenderSystem(entry.system) && isDrawn || !isRenderSystem(entry.system) && isUpdated {
+				active = insertSystem(active, entry)
+			}
+		}
+	}
+
+	e.activeBuffer = active
+
+	return active
+}
+
+// Calls given function for all global systems and systems owned by scenes, without allocating.
+func (e *Engine) eachSystem(f func(System)) {
+	for _, entry := range e.systems {
+		f(entry.system)
+	}
+
+	for _, scene := range e.scenes {
+		if data := e.sceneData[scene]; data != nil {
+			for _, entry := range data.systems {
+				f(entry.system)
+			}
+		}
+	}
+}
+
+func (e *Engine) updateSimulationSystems(delta float64) {
+	for _, entry := range e.activeSystems(e.sceneStack) {
+		if !isRenderSystem(entry.system) {
+			entry.system.Update(delta)
+		}
+	}
+}
+
+// Updates global render systems and render systems owned by given scene stack.
+func (e *Engine) updateRenderSystems(stack []Scene, delta float64) {
+	for _, entry := range e.activeSystems(stack) {
+		if isRenderSystem(entry.system) {
+			entry.system.Update(delta)
+		}
 	}
 }
 
+func isRenderSystem(system System) bool {
+	render, ok := system.(RenderSystem)
+	return ok && render.IsRenderSystem()
+}
+
 // Removes an actor from all systems.
-// This maybe not as performant as directly removing it from the right system.
 // Returns true if it could be removed from at least one system, else false.
 func RemoveActor(actor *Actor) bool {
-	return RemoveActorById(actor.GetId())
+	return defaultEngine.RemoveActor(actor)
+}
+
+// Removes an actor from all systems.
+// Returns true if it could be removed from at least one system, else false.
+func (e *Engine) RemoveActor(actor *Actor) bool {
+	return e.RemoveActorById(actor.GetId())
 }
 
 // Removes an actor from all systems by ID.
-// This maybe not as performant as directly removing it from the right system.
 // Returns true if it could be removed from at least one system, else false.
 func RemoveActorById(id ActorId) bool {
+	return defaultEngine.RemoveActorById(id)
+}
+
+// Removes an actor from all systems by ID.
+// Returns true if it could be removed from at least one system, else false.
+func (e *Engine) RemoveActorById(id ActorId) bool {
 	removed := false
 
-	for _, system := range systems {
+	e.eachSystem(func(system System) {
+		if lookup, ok := system.(ActorLookup); ok && !lookup.Has(id) {
+			return
+		}
+
 		if system.RemoveById(id) {
 			removed = true
 		}
-	}
+	})
 
 	return removed
 }
@@ -0,0 +1,87 @@
+package goga
+
+import (
+	"testing"
+)
+
+const (
+	bench_actors = 100000
+)
+
+// Creates a sprite renderer without GL resources.
+func newBenchSpriteRenderer() *SpriteRenderer {
+	return &SpriteRenderer{sprites: make([]Sprite, 0), entries: newTombstoneIndex()}
+}
+
+func newBenchActors() ([]*Actor, *Pos2D, *Tex) {
+	actors := make([]*Actor, bench_actors)
+
+	for i := range actors {
+		actors[i] = NewActor()
+	}
+
+	return actors, NewPos2D(), &Tex{}
+}
+
+func BenchmarkSpriteRendererAddRemove(b *testing.B) {
+	actors, pos, tex := newBenchActors()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		renderer := newBenchSpriteRenderer()
+
+		for _, actor := range actors {
+			renderer.Add(actor, pos, tex)
+		}
+
+		for _, actor := range actors {
+			renderer.RemoveById(actor.GetId())
+		}
+	}
+}
+
+func BenchmarkCulling2DAddRemove(b *testing.B) {
+	actors, pos, _ := newBenchActors()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		culling := NewCulling2D(0, 0, 800, 600)
+
+		for _, actor := range actors {
+			culling.Add(actor, pos)
+		}
+
+		for _, actor := range actors {
+			culling.RemoveById(actor.GetId())
+		}
+	}
+}
+
+func BenchmarkRemoveActorById(b *testing.B) {
+	actors, pos, tex := newBenchActors()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		e := NewEngine()
+		renderer := newBenchSpriteRenderer()
+		culling := NewCulling2D(0, 0, 800, 600)
+		e.AddSystem(renderer)
+		e.AddSystem(culling)
+
+		for _, actor := range actors {
+			renderer.Add(actor, pos, tex)
+			culling.Add(actor, pos)
+		}
+
+		b.StartTimer()
+
+		for _, actor := range actors {
+			e.RemoveActorById(actor.GetId())
+		}
+
+		if renderer.Len() != 0 || culling.Len() != 0 {
+			b.Fatal("Expected all actors to be removed")
+		}
+	}
+}
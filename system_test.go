@@ -0,0 +1,221 @@
+package goga
+
+import (
+	"testing"
+)
+
+// Removes actors from all systems when updated.
+type testRemoveSystem struct {
+	testSystem
+	engine *Engine
+	actors []*Actor
+}
+
+func (s *testRemoveSystem) Update(delta float64) {
+	for _, actor := range s.actors {
+		s.engine.RemoveActorById(actor.GetId())
+	}
+}
+
+func TestRemoveActorByIdWhileCompacting(t *testing.T) {
+	e := NewEngine()
+	scene := &testScene{name: "level"}
+	e.AddScene(scene)
+	renderer := newBenchSpriteRenderer()
+	sceneRenderer := newBenchSpriteRenderer()
+	culling := NewCulling2D(0, 0, 800, 600)
+	actors := make([]*Actor, 20)
+	pos, tex := NewPos2D(), &Tex{}
+
+	for i := range actors {
+		actors[i] = NewActor()
+		renderer.Add(actors[i], pos, tex)
+		sceneRenderer.Add(actors[i], pos, tex)
+		culling.Add(actors[i], pos)
+	}
+
+	// removing 16 of 20 actors compacts the renderers after the 11th and 16th actor,
+	// so that the following actors are removed using updated indices
+	remover := &testRemoveSystem{engine: e}
+	kept := []*Actor{actors[1], actors[3], actors[12], actors[18]}
+
+	for i, actor := range actors {
+		if i != 1 && i != 3 && i != 12 && i != 18 {
+			remover.actors = append(remover.actors, actor)
+		}
+	}
+
+	e.AddSystemWithPriority(remover, -1)
+	e.AddSystem(renderer)
+	e.AddSystem(culling)
+	e.AddSceneSystem(scene, sceneRenderer)
+	e.updateSimulationSystems(0)
+
+	for _, r := range []*SpriteRenderer{renderer, sceneRenderer} {
+		if r.Len() != len(kept) || len(r.sprites) != len(kept) {
+			t.Fatalf("Expected %v sprites to be kept, got %v", len(kept), r.Len())
+		}
+
+		for i, actor := range kept {
+			if !r.Has(actor.GetId()) || r.sprites[i].Actor != actor {
+				t.Fatalf("Expected sprite %v to be kept in order, got %v", actor.GetId(), r.sprites[i].Actor)
+			}
+		}
+
+		for _, actor := range remover.actors {
+			if r.Has(actor.GetId()) {
+				t.Fatalf("Expected sprite %v to be removed", actor.GetId())
+			}
+		}
+	}
+
+	if culling.Len() != len(kept) {
+		t.Fatalf("Expected %v actors to be kept by culling, got %v", len(kept), culling.Len())
+	}
+}
+
+// Appends its name to order when updated.
+type testNamedSystem struct {
+	testSystem
+	name  string
+	order *[]string
+}
+
+func (s *testNamedSystem) Update(delta float64) {
+	*s.order = append(*s.order, s.name)
+}
+
+func (s *testNamedSystem) GetName() string {
+	return s.name
+}
+
+// Adds systems named by priority and returns the order they are updated in.
+func newTestNamedSystems(e *Engine, order *[]string, systems map[string]int, names ...string) {
+	for _, name := range names {
+		e.AddSystemWithPriority(&testNamedSystem{name: name, order: order}, systems[name])
+	}
+}
+
+func updateOrder(e *Engine, order *[]string) []string {
+	*order = (*order)[:0]
+	e.updateSimulationSystems(0)
+	return *order
+}
+
+func TestSystemPriority(t *testing.T) {
+	e := NewEngine()
+	order := make([]string, 0)
+	newTestNamedSystems(e, &order, map[string]int{"a": 10, "b": -5, "c": 10, "d": 0}, "a", "b", "c", "d")
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"b", "d", "a", "c"}) {
+		t.Fatalf("Expected systems ordered by priority and insertion, got %v", got)
+	}
+
+	if !e.SetSystemPriority("a", 20) || !e.SetSystemPriority("c", -5) {
+		t.Fatal("Expected priorities to be set")
+	}
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"b", "c", "d", "a"}) {
+		t.Fatalf("Expected systems to be reordered, got %v", got)
+	}
+
+	if priority, ok := e.GetSystemPriority("c"); !ok || priority != -5 {
+		t.Fatalf("Expected priority -5, got %v", priority)
+	}
+
+	if e.SetSystemPriority("missing", 0) {
+		t.Fatal("Expected missing system not to be found")
+	}
+}
+
+func TestMoveSystem(t *testing.T) {
+	e := NewEngine()
+	order := make([]string, 0)
+	newTestNamedSystems(e, &order, map[string]int{"a": 0, "b": 10, "c": 20}, "a", "b", "c")
+
+	if !e.MoveSystemBefore("c", "a") {
+		t.Fatal("Expected system to be moved")
+	}
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"c", "a", "b"}) {
+		t.Fatalf("Expected system to be moved before, got %v", got)
+	}
+
+	if priority, _ := e.GetSystemPriority("c"); priority != 0 {
+		t.Fatalf("Expected moved system to take over priority, got %v", priority)
+	}
+
+	if !e.MoveSystemAfter("c", "b") {
+		t.Fatal("Expected system to be moved")
+	}
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"a", "b", "c"}) {
+		t.Fatalf("Expected system to be moved after, got %v", got)
+	}
+
+	// systems added later keep being inserted by priority
+	e.AddSystemWithPriority(&testNamedSystem{name: "d", order: &order}, 10)
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("Expected new system behind systems of equal priority, got %v", got)
+	}
+
+	if e.MoveSystemBefore("a", "a") || e.MoveSystemAfter("a", "missing") || e.MoveSystemBefore("missing", "a") {
+		t.Fatal("Expected invalid moves to fail")
+	}
+}
+
+func TestSetSystemEnabled(t *testing.T) {
+	e := NewEngine()
+	scene := &testScene{name: "level"}
+	e.AddScene(scene)
+	order := make([]string, 0)
+	newTestNamedSystems(e, &order, map[string]int{"a": 0, "b": 10}, "a", "b")
+	e.AddSceneSystemWithPriority(scene, &testNamedSystem{name: "scene", order: &order}, 5)
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"a", "scene", "b"}) {
+		t.Fatalf("Expected scene system to be merged by priority, got %v", got)
+	}
+
+	if !e.SetSystemEnabled("a", false) || !e.SetSystemEnabled("scene", false) || e.IsSystemEnabled("a") || e.IsSystemEnabled("scene") {
+		t.Fatal("Expected global and scene systems to be disabled")
+	}
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"b"}) {
+		t.Fatalf("Expected disabled systems not to be updated, got %v", got)
+	}
+
+	e.SetSystemEnabled("a", true)
+	e.SetSystemEnabled("scene", true)
+
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"a", "scene", "b"}) {
+		t.Fatalf("Expected enabled systems to be updated, got %v", got)
+	}
+
+	if e.SetSystemEnabled("missing", true) || e.IsSystemEnabled("missing") {
+		t.Fatal("Expected missing system not to be found")
+	}
+}
+
+func TestActiveSystemsOrder(t *testing.T) {
+	e := NewEngine()
+	bottom := &testScene{name: "bottom"}
+	top := &testOverlayScene{testScene{name: "top"}, true, true}
+	e.AddScene(bottom)
+	e.AddScene(top)
+	e.PushScene(top)
+	order := make([]string, 0)
+	newTestNamedSystems(e, &order, map[string]int{"global": 0}, "global")
+	e.AddSceneSystemWithPriority(top, &testNamedSystem{name: "top", order: &order}, 0)
+	e.AddSceneSystemWithPriority(bottom, &testNamedSystem{name: "bottom", order: &order}, 0)
+	e.AddSceneSystemWithPriority(bottom, &testNamedSystem{name: "first", order: &order}, -1)
+
+	// on equal priority, global systems come first, then scene systems from bottom to top
+	if got := updateOrder(e, &order); !equalStrings(got, []string{"first", "global", "bottom", "top"}) {
+		t.Fatalf("Expected systems ordered by priority, global and scene order, got %v", got)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { e.activeSystems(e.sceneStack) }); allocs != 0 {
+		t.Fatalf("Expected active systems not to allocate, got %v allocations", allocs)
+	}
+}
@@ -1,7 +1,11 @@
 package goga
 
 func GetSpriteRenderer() *SpriteRenderer {
-	renderer, ok := GetSystemByName(sprite_renderer_name).(*SpriteRenderer)
+	return defaultEngine.GetSpriteRenderer()
+}
+
+func (e *Engine) GetSpriteRenderer() *SpriteRenderer {
+	renderer, ok := e.GetSystemByName(sprite_renderer_name).(*SpriteRenderer)
 
 	if !ok {
 		panic("Could not obtain sprite renderer")
@@ -11,7 +15,11 @@ func GetSpriteRenderer() *SpriteRenderer {
 }
 
 func GetModelRenderer() *ModelRenderer {
-	renderer, ok := GetSystemByName(model_renderer_name).(*ModelRenderer)
+	return defaultEngine.GetModelRenderer()
+}
+
+func (e *Engine) GetModelRenderer() *ModelRenderer {
+	renderer, ok := e.GetSystemByName(model_renderer_name).(*ModelRenderer)
 
 	if !ok {
 		panic("Could not obtain model renderer")
@@ -21,7 +29,11 @@ func GetModelRenderer() *ModelRenderer {
 }
 
 func GetCulling2DSystem() *Culling2D {
-	system, ok := GetSystemByName(culling_2d_name).(*Culling2D)
+	return defaultEngine.GetCulling2DSystem()
+}
+
+func (e *Engine) GetCulling2DSystem() *Culling2D {
+	system, ok := e.GetSystemByName(culling_2d_name).(*Culling2D)
 
 	if !ok {
 		panic("Could not obtain culling system")
@@ -31,7 +43,11 @@ func GetCulling2DSystem() *Culling2D {
 }
 
 func GetKeyframeRenderer() *KeyframeRenderer {
-	renderer, ok := GetSystemByName(keyframe_sprite_renderer_name).(*KeyframeRenderer)
+	return defaultEngine.GetKeyframeRenderer()
+}
+
+func (e *Engine) GetKeyframeRenderer() *KeyframeRenderer {
+	renderer, ok := e.GetSystemByName(keyframe_sprite_renderer_name).(*KeyframeRenderer)
 
 	if !ok {
 		panic("Could not obtain keyframe renderer")
@@ -41,7 +57,11 @@ func GetKeyframeRenderer() *KeyframeRenderer {
 }
 
 func GetTextRenderer() *TextRenderer {
-	renderer, ok := GetSystemByName(text_renderer_name).(*TextRenderer)
+	return defaultEngine.GetTextRenderer()
+}
+
+func (e *Engine) GetTextRenderer() *TextRenderer {
+	renderer, ok := e.GetSystemByName(text_renderer_name).(*TextRenderer)
 
 	if !ok {
 		panic("Could not obtain text renderer")
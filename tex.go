@@ -1,6 +1,7 @@
 package goga
 
 import (
+	"errors"
 	"github.com/go-gl/gl/v3.2-core/gl"
 	"image"
 )
@@ -15,6 +16,7 @@ type Tex struct {
 	target        uint32
 	activeTexture uint32
 	size          Vec3
+	frames        int
 	rgba          *image.RGBA // optional, most of the time nil
 }
 
@@ -30,7 +32,26 @@ func NewTex(target uint32) *Tex {
 
 // Drops the texture.
 func (t *Tex) Drop() {
-	gl.DeleteBuffers(1, &t.id)
+	gl.DeleteTextures(1, &t.id)
+}
+
+// Replaces the texture by given texture, which must be a *Tex.
+// The GL texture is dropped and replaced, so that the pointer stays valid on hot reload.
+func (t *Tex) Swap(res Res) error {
+	tex, ok := res.(*Tex)
+
+	if !ok {
+		return errors.New("Resource " + res.GetName() + " is not a texture")
+	}
+
+	t.Drop()
+	t.id = tex.id
+	t.target = tex.target
+	t.size = tex.size
+	t.frames = tex.frames
+	t.rgba = tex.rgba
+
+	return nil
 }
 
 // Returns the name of this resource.
@@ -163,3 +184,13 @@ func (t *Tex) GetSize() Vec3 {
 func (t *Tex) GetRGBA() *image.RGBA {
 	return t.rgba
 }
+
+// Returns the number of frames placed next to each other, like for gif files loaded as strip.
+// This is 1 for all other textures.
+func (t *Tex) GetFrames() int {
+	if t.frames < 1 {
+		return 1
+	}
+
+	return t.frames
+}
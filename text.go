@@ -2,8 +2,9 @@ package goga
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/go-gl/gl/v3.2-core/gl"
-	"io/ioutil"
+	"io"
 )
 
 const (
@@ -30,6 +31,9 @@ type Font struct {
 	CharPadding      Vec2
 	Space, Tab, Line float64
 	chars            []character
+	jsonPath         string
+	jsonEngine       *Engine
+	cut              bool
 }
 
 // Creates a new font for given texture.
@@ -51,31 +55,62 @@ func NewFont(tex *Tex, tileSize float64) *Font {
 // Loads characters from JSON file.
 // Format:
 //
-// [
-//     {
-//         "char": "a",
-//         "x": 0,
-//         "y": 0,
-//		   "offset": 0
-//     },
-//     ...
-// ]
+//	[
+//	    {
+//	        "char": "a",
+//	        "x": 0,
+//	        "y": 0,
+//	        "offset": 0
+//	    },
+//	    ...
+//	]
 //
 // Where x and y start in the upper left corner of the texture, both of type int.
 // Offset is optional and can be used to move a character up or down (relative to others).
 // If cut is set to true, the characters will be true typed.
+// The file is opened from the virtual file system of the default engine, see MountFS().
 func (f *Font) FromJson(path string, cut bool) error {
-	// load file content
-	content, err := ioutil.ReadFile(path)
+	return defaultEngine.LoadFontJson(f, path, cut)
+}
+
+// Loads characters of the font from JSON file.
+// The file is opened from the virtual file system of this engine.
+// See Font.FromJson() for details.
+func (e *Engine) LoadFontJson(font *Font, path string, cut bool) error {
+	file, err := e.OpenFile(path)
 
 	if err != nil {
 		return err
 	}
 
-	// read json
+	defer file.Close()
+	font.jsonPath = path
+	font.jsonEngine = e
+	font.cut = cut
+
+	return font.FromJsonReader(file, cut)
+}
+
+// Reloads the characters from the JSON file last loaded using FromJson() or LoadFontJson(),
+// using the same engine.
+// Use it as reload callback of the font texture or with WatchFile() to update the font on change.
+// Texts must be set again to use the new characters.
+func (f *Font) Reload() error {
+	if f.jsonPath == "" {
+		return errors.New("Font was not loaded from JSON file")
+	}
+
+	f.chars = make([]character, 0)
+
+	return f.jsonEngine.LoadFontJson(f, f.jsonPath, f.cut)
+}
+
+// Loads characters from JSON.
+// See FromJson() for details.
+func (f *Font) FromJsonReader(r io.Reader, cut bool) error {
 	chars := make([]jsonChar, 0)
 
-	if err = json.Unmarshal(content, &chars); err != nil {
+	if err := json.NewDecoder(r).Decode(&chars); err != nil {
 		return err
 	}
 
@@ -167,6 +202,7 @@ type TextComponent struct {
 	bounds                  Vec2
 	index, vertex, texCoord *VBO
 	vao                     *VAO
+	prepared                bool
 }
 
 // Deletes GL buffers bound to this text component.
@@ -174,7 +210,11 @@ func (t *TextComponent) Drop() {
 	t.index.Drop()
 	t.vertex.Drop()
 	t.texCoord.Drop()
-	t.vao.Drop()
+
+	// the VAO is created when the text gets prepared for rendering
+	if t.vao != nil {
+		t.vao.Drop()
+	}
 }
 
 // Text is an actor representing text rendered as texture mapped font.
@@ -190,22 +230,30 @@ func NewText(font *Font, textStr string) *Text {
 	text := Text{}
 	text.Actor = NewActor()
 	text.Pos2D = NewPos2D()
-	text.TextComponent = &TextComponent{}
+	text.TextComponent = NewTextComponent(font, textStr)
+	text.Size = Vec2{1, 1}
+	text.Scale = Vec2{1, 1}
+	text.Visible = true
+
+	return &text
+}
+
+// Returns a new text component using given font and string.
+// It must be prepared by a TextRenderer to be rendered.
+func NewTextComponent(font *Font, textStr string) *TextComponent {
+	text := &TextComponent{}
 	text.index = NewVBO(gl.ELEMENT_ARRAY_BUFFER)
 	text.vertex = NewVBO(gl.ARRAY_BUFFER)
 	text.texCoord = NewVBO(gl.ARRAY_BUFFER)
 	text.vao = NewVAO()
 	text.SetText(font, textStr)
 	text.Color = Vec4{1, 1, 1, 1}
-	text.Size = Vec2{1, 1}
-	text.Scale = Vec2{1, 1}
-	text.Visible = true
 
-	return &text
+	return text
 }
 
 // Sets the given string as text and (re)creates buffers.
-func (t *Text) SetText(font *Font, text string) {
+func (t *TextComponent) SetText(font *Font, text string) {
 	t.text = text
 	indices := make([]uint32, len(text)*6)
 	vertices := make([]float32, len(text)*8)
@@ -303,7 +351,7 @@ func (t *Text) SetText(font *Font, text string) {
 }
 
 // Returns the text as string.
-func (t *Text) GetText() string {
+func (t *TextComponent) GetText() string {
 	return t.text
 }
 
@@ -320,7 +368,9 @@ type TextRenderer struct {
 	Shader *Shader
 	Camera *Camera
 	Font   *Font
-	texts  []Text
+
+	texts   []Text
+	entries tombstoneIndex
 }
 
 // Creates a new text renderer using given shader, camera and font.
@@ -339,6 +389,7 @@ func NewTextRenderer(shader *Shader, camera *Camera, font *Font) *TextRenderer {
 	renderer.Camera = camera
 	renderer.Font = font
 	renderer.texts = make([]Text, 0)
+	renderer.entries = newTombstoneIndex()
 	renderer.Size = Vec2{1, 1}
 	renderer.Scale = Vec2{1, 1}
 
@@ -347,6 +398,14 @@ func NewTextRenderer(shader *Shader, camera *Camera, font *Font) *TextRenderer {
 
 // Prepares given text for rendering.
 func (r *TextRenderer) Prepare(text *Text) {
+	r.prepareComponent(text.TextComponent)
+}
+
+func (r *TextRenderer) prepareComponent(text *TextComponent) {
+	if text.vao != nil {
+		text.vao.Drop()
+	}
+
 	text.vao = NewVAO()
 	text.vao.Bind()
 	r.Shader.EnableVertexAttribArrays()
@@ -356,11 +415,14 @@ func (r *TextRenderer) Prepare(text *Text) {
 	text.texCoord.Bind()
 	text.texCoord.AttribPointer(r.Shader.GetAttribLocation(Default_shader_text_texcoord_attrib), 2, gl.FLOAT, false, 0)
 	text.vao.Unbind()
+	text.prepared = true
 }
 
 // Frees recources created by text component.
 // This is called automatically when system gets removed.
 func (r *TextRenderer) Cleanup() {
+	r.compact()
+
 	for _, text := range r.texts {
 		text.Drop()
 	}
@@ -370,10 +432,8 @@ func (r *TextRenderer) Cleanup() {
 func (r *TextRenderer) Add(actor *Actor, pos *Pos2D, text *TextComponent) bool {
 	id := actor.GetId()
 
-	for _, text := range r.texts {
-		if id == text.Actor.GetId() {
-			return false
-		}
+	if !r.entries.add(id, len(r.texts)) {
+		return false
 	}
 
 	r.texts = append(r.texts, Text{actor, pos, text})
@@ -381,6 +441,24 @@ func (r *TextRenderer) Add(actor *Actor, pos *Pos2D, text *TextComponent) bool {
 	return true
 }
 
+// Returns the query for actors rendered as text.
+func (r *TextRenderer) GetQuery() *ComponentQuery {
+	return &ComponentQuery{Require: []ComponentType{Pos2DType, TextComponentType}}
+}
+
+// Adds text to the renderer from component store.
+// The text is prepared if that has not been done yet.
+func (r *TextRenderer) AddFromStore(actor *Actor, store *ComponentStore) bool {
+	id := actor.GetId()
+	text := store.GetTextComponent(id)
+
+	if !text.prepared {
+		r.prepareComponent(text)
+	}
+
+	return r.Add(actor, store.GetPos2D(id), text)
+}
+
 // Removes text from renderer.
 func (r *TextRenderer) Remove(actor *Actor) bool {
 	return r.RemoveById(actor.GetId())
@@ -388,24 +466,51 @@ func (r *TextRenderer) Remove(actor *Actor) bool {
 
 // Removes text from renderer by ID.
 func (r *TextRenderer) RemoveById(id ActorId) bool {
-	for i, text := range r.texts {
-		if text.Actor.GetId() == id {
-			r.texts = append(r.texts[:i], r.texts[i+1:]...)
-			return true
-		}
+	i, ok := r.entries.remove(id)
+
+	if !ok {
+		return false
+	}
+
+	// keep render order, removed entries are compacted on next update
+	// or as soon as more than half of them are removed
+	r.texts[i] = Text{}
+
+	if r.entries.mostlyRemoved(len(r.texts)) {
+		r.compact()
 	}
 
-	return false
+	return true
 }
 
 // Removes all texts.
 func (r *TextRenderer) RemoveAll() {
 	r.texts = make([]Text, 0)
+	r.entries.reset()
 }
 
 // Returns number of texts.
 func (r *TextRenderer) Len() int {
-	return len(r.texts)
+	return r.entries.len(len(r.texts))
+}
+
+// Returns true if the text with given actor ID was added to the renderer.
+func (r *TextRenderer) Has(id ActorId) bool {
+	return r.entries.has(id)
+}
+
+// Removes entries left by RemoveById() and updates indices, keeping the order.
+func (r *TextRenderer) compact() {
+	n := r.entries.compact(len(r.texts),
+		func(i int) *Actor { return r.texts[i].Actor },
+		func(from, to int) { r.texts[to] = r.texts[from] },
+		func(i int) { r.texts[i] = Text{} })
+	r.texts = r.texts[:n]
+}
+
+// Returns true, as this is a render system.
+func (r *TextRenderer) IsRenderSystem() bool {
+	return true
 }
 
 func (r *TextRenderer) GetName() string {
@@ -414,6 +519,8 @@ func (r *TextRenderer) GetName() string {
 
 // Renders texts.
 func (r *TextRenderer) Update(delta float64) {
+	r.compact()
+
 	if r.Font == nil {
 		return
 	}
@@ -424,13 +531,13 @@ func (r *TextRenderer) Update(delta float64) {
 	r.Font.Tex.Bind()
 
 	for _, text := range r.texts {
-		if !text.Visible {
+		if text.Actor == nil || !text.Visible {
 			continue
 		}
 
 		text.vao.Bind()
 		r.Shader.SendUniform4f(Default_shader_text_color, float32(text.Color.X), float32(text.Color.Y), float32(text.Color.Z), float32(text.Color.W))
-		r.Shader.SendMat3(Default_shader_text_model, *text.CalcModel())
+		r.Shader.SendMat3(Default_shader_text_model, *text.CalcWorld())
 
 		gl.DrawElements(gl.TRIANGLES, text.index.Size(), gl.UNSIGNED_INT, nil)
 	}
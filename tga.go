@@ -0,0 +1,214 @@
+package goga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+const (
+	tga_type_color_mapped = 1
+	tga_type_true_color   = 2
+	tga_type_gray         = 3
+	tga_type_rle          = 8
+
+	tga_descriptor_alpha      = 0x0F
+	tga_descriptor_right_left = 0x10
+	tga_descriptor_top_bottom = 0x20
+	tga_header_size           = 18
+
+	// upper limit for the image size, as it is read from file
+	tga_max_pixels = 1 << 26
+)
+
+// Header of a tga file.
+type tgaHeader struct {
+	idLength     int
+	colorMapType int
+	imageType    int
+	mapFirst     int
+	mapLength    int
+	mapDepth     int
+	width        int
+	height       int
+	depth        int
+	descriptor   byte
+}
+
+// Decodes a tga file of color mapped, true color or grayscale type, uncompressed or run-length encoded.
+// Supported pixel depths are 8 bits for grayscale and color mapped, 15, 16, 24 and 32 bits for true color.
+func decodeTga(r io.Reader) (image.Image, error) {
+	reader := bufio.NewReader(r)
+	header, err := readTgaHeader(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.CopyN(io.Discard, reader, int64(header.idLength)); err != nil {
+		return nil, errors.New("Unexpected end of tga file")
+	}
+
+	var palette []color.NRGBA
+	alpha := header.descriptor&tga_descriptor_alpha > 0
+
+	if header.colorMapType == 1 {
+		palette, err = readTgaColorMap(reader, header, alpha)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	imageType := header.imageType &^ tga_type_rle
+	pixel := make([]byte, (header.depth+7)/8)
+	img := image.NewNRGBA(image.Rect(0, 0, header.width, header.height))
+	count := header.width * header.height
+
+	for i := 0; i < count; {
+		n, repeat := 1, false
+
+		if header.imageType&tga_type_rle != 0 {
+			packet, err := reader.ReadByte()
+
+			if err != nil {
+				return nil, errors.New("Unexpected end of tga file")
+			}
+
+			n, repeat = int(packet&0x7F)+1, packet&0x80 != 0
+		}
+
+		for j := 0; j < n && i < count; j++ {
+			if !repeat || j == 0 {
+				if _, err := io.ReadFull(reader, pixel); err != nil {
+					return nil, errors.New("Unexpected end of tga file")
+				}
+			}
+
+			c, err := tgaPixel(pixel, imageType, header, palette, alpha)
+
+			if err != nil {
+				return nil, err
+			}
+
+			x, y := i%header.width, i/header.width
+
+			if header.descriptor&tga_descriptor_top_bottom == 0 {
+				y = header.height - 1 - y
+			}
+
+			if header.descriptor&tga_descriptor_right_left != 0 {
+				x = header.width - 1 - x
+			}
+
+			img.SetNRGBA(x, y, c)
+			i++
+		}
+	}
+
+	return img, nil
+}
+
+// Reads and validates the tga header.
+func readTgaHeader(r io.Reader) (*tgaHeader, error) {
+	data := make([]byte, tga_header_size)
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.New("Unexpected end of tga header")
+	}
+
+	header := &tgaHeader{int(data[0]),
+		int(data[1]),
+		int(data[2]),
+		int(binary.LittleEndian.Uint16(data[3:])),
+		int(binary.LittleEndian.Uint16(data[5:])),
+		int(data[7]),
+		int(binary.LittleEndian.Uint16(data[12:])),
+		int(binary.LittleEndian.Uint16(data[14:])),
+		int(data[16]),
+		data[17]}
+	imageType := header.imageType &^ tga_type_rle
+
+	switch {
+	case imageType == tga_type_color_mapped && header.depth == 8 && header.colorMapType == 1:
+	case imageType == tga_type_true_color && (header.depth == 15 || header.depth == 16 || header.depth == 24 || header.depth == 32):
+	case imageType == tga_type_gray && header.depth == 8:
+	default:
+		return nil, errors.New("Unsupported tga type " + strconv.Itoa(header.imageType) + " with depth " + strconv.Itoa(header.depth))
+	}
+
+	if header.width == 0 || header.height == 0 || header.width*header.height > tga_max_pixels {
+		return nil, errors.New("Invalid tga size " + strconv.Itoa(header.width) + "x" + strconv.Itoa(header.height))
+	}
+
+	return header, nil
+}
+
+// Reads the color map of a tga file.
+func readTgaColorMap(r io.Reader, header *tgaHeader, alpha bool) ([]color.NRGBA, error) {
+	if header.mapDepth != 15 && header.mapDepth != 16 && header.mapDepth != 24 && header.mapDepth != 32 {
+		return nil, errors.New("Unsupported tga color map depth " + strconv.Itoa(header.mapDepth))
+	}
+
+	palette := make([]color.NRGBA, 0, header.mapLength)
+	entry := make([]byte, (header.mapDepth+7)/8)
+
+	for i := 0; i < header.mapLength; i++ {
+		if _, err := io.ReadFull(r, entry); err != nil {
+			return nil, errors.New("Unexpected end of tga color map")
+		}
+
+		palette = append(palette, tgaColor(entry, header.mapDepth, alpha))
+	}
+
+	return palette, nil
+}
+
+// Returns the color of a pixel, looking up color mapped pixels.
+func tgaPixel(pixel []byte, imageType int, header *tgaHeader, palette []color.NRGBA, alpha bool) (color.NRGBA, error) {
+	if imageType == tga_type_gray {
+		return color.NRGBA{pixel[0], pixel[0], pixel[0], 255}, nil
+	}
+
+	if imageType == tga_type_true_color {
+		return tgaColor(pixel, header.depth, alpha), nil
+	}
+
+	index := int(pixel[0]) - header.mapFirst
+
+	if index < 0 || index >= len(palette) {
+		return color.NRGBA{}, errors.New("Color map index out of range")
+	}
+
+	return palette[index], nil
+}
+
+// Converts a BGR(A) encoded color of given depth.
+// The alpha channel is used only if the image has alpha bits.
+func tgaColor(data []byte, depth int, alpha bool) color.NRGBA {
+	switch depth {
+	case 15, 16:
+		v := binary.LittleEndian.Uint16(data)
+		c := color.NRGBA{uint8((v >> 10 & 0x1F) * 255 / 31), uint8((v >> 5 & 0x1F) * 255 / 31), uint8((v & 0x1F) * 255 / 31), 255}
+
+		if depth == 16 && alpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+
+		return c
+	case 24:
+		return color.NRGBA{data[2], data[1], data[0], 255}
+	}
+
+	c := color.NRGBA{data[2], data[1], data[0], 255}
+
+	if alpha {
+		c.A = data[3]
+	}
+
+	return c
+}
@@ -0,0 +1,88 @@
+package goga
+
+// Maps actor IDs to entries of a slice kept in insertion (render) order.
+// Removed entries are left empty and compacted later,
+// so that removing an actor does not move all following entries.
+type tombstoneIndex struct {
+	indices map[ActorId]int
+	removed int
+}
+
+func newTombstoneIndex() tombstoneIndex {
+	return tombstoneIndex{indices: make(map[ActorId]int)}
+}
+
+// Adds the entry at index i.
+// Returns false if the actor has been added before.
+func (t *tombstoneIndex) add(id ActorId, i int) bool {
+	if _, ok := t.indices[id]; ok {
+		return false
+	}
+
+	t.indices[id] = i
+
+	return true
+}
+
+// Removes the entry and returns the index to be emptied, or false if not found.
+func (t *tombstoneIndex) remove(id ActorId) (int, bool) {
+	i, ok := t.indices[id]
+
+	if !ok {
+		return 0, false
+	}
+
+	delete(t.indices, id)
+	t.removed++
+
+	return i, true
+}
+
+// Returns true if the actor has been added.
+func (t *tombstoneIndex) has(id ActorId) bool {
+	_, ok := t.indices[id]
+	return ok
+}
+
+// Removes all entries.
+func (t *tombstoneIndex) reset() {
+	t.indices = make(map[ActorId]int)
+	t.removed = 0
+}
+
+// Returns the number of entries for a slice of length n.
+func (t *tombstoneIndex) len(n int) int {
+	return n - t.removed
+}
+
+// Returns true if more than half of the entries of a slice of length n are removed.
+func (t *tombstoneIndex) mostlyRemoved(n int) bool {
+	return t.removed > n/2
+}
+
+// Moves entries of a slice of length n to the front, keeping the order, and updates indices.
+// Entry i is empty if actor(i) returns nil. Move(from, to) must copy an entry,
+// clear(i) must empty an entry behind the returned new length.
+func (t *tombstoneIndex) compact(n int, actor func(int) *Actor, move func(int, int), clear func(int)) int {
+	if t.removed == 0 {
+		return n
+	}
+
+	compacted := 0
+
+	for i := 0; i < n; i++ {
+		if a := actor(i); a != nil {
+			move(i, compacted)
+			t.indices[a.GetId()] = compacted
+			compacted++
+		}
+	}
+
+	for i := compacted; i < n; i++ {
+		clear(i)
+	}
+
+	t.removed = 0
+
+	return compacted
+}
@@ -0,0 +1,31 @@
+package goga
+
+import (
+	"testing"
+)
+
+func TestSpriteRendererRemoveCompacts(t *testing.T) {
+	renderer := newBenchSpriteRenderer()
+	actors := []*Actor{NewActor(), NewActor(), NewActor(), NewActor()}
+
+	for _, actor := range actors {
+		renderer.Add(actor, NewPos2D(), &Tex{})
+	}
+
+	renderer.RemoveById(actors[0].GetId())
+	renderer.RemoveById(actors[2].GetId())
+
+	if len(renderer.sprites) != 4 || renderer.Len() != 2 {
+		t.Fatalf("Expected removed entries to be kept until more than half are removed, got %v entries", len(renderer.sprites))
+	}
+
+	renderer.RemoveById(actors[1].GetId())
+
+	if len(renderer.sprites) != 1 || renderer.sprites[0].Actor != actors[3] || renderer.entries.indices[actors[3].GetId()] != 0 {
+		t.Fatal("Expected entries to be compacted on remove")
+	}
+
+	if renderer.Add(actors[3], NewPos2D(), &Tex{}) || !renderer.Add(actors[0], NewPos2D(), &Tex{}) || !renderer.Has(actors[0].GetId()) {
+		t.Fatal("Expected index to be updated after compaction")
+	}
+}
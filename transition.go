@@ -0,0 +1,245 @@
+package goga
+
+import (
+	"github.com/go-gl/gl/v3.2-core/gl"
+	"log"
+	"math"
+)
+
+// A transition animates the switch between two scenes.
+// The outgoing and incoming scenes are rendered to textures each frame,
+// Draw() must then render them to screen using the given progress [0, 1].
+// Implement this interface to add custom transitions.
+type Transition interface {
+	Draw(renderer *TransitionRenderer, from, to *Tex, progress float64)
+}
+
+// Running transition and its render targets.
+type transitionState struct {
+	transition         Transition
+	from               []Scene
+	duration, time     float64
+	fromFbo, toFbo     *FBO
+	fromTex, toTex     *Tex
+	fromDepth, toDepth *RBO
+}
+
+// The transition renderer draws full screen textures for transitions.
+type TransitionRenderer struct {
+	Shader *Shader
+
+	index, vertex *VBO
+	vao           *VAO
+}
+
+// Creates a new transition renderer with its own shader.
+// This function will panic if the shader cannot be created.
+func NewTransitionRenderer() *TransitionRenderer {
+	shader, err := NewShader(default_shader_transition_vertex_src, default_shader_transition_fragment_src)
+
+	if err != nil {
+		panic(err)
+	}
+
+	shader.BindAttrib(Default_shader_transition_vertex_attrib)
+
+	var tc *VBO
+
+	renderer := &TransitionRenderer{}
+	renderer.Shader = shader
+	renderer.index, renderer.vertex, tc = CreateRectMesh(true)
+	tc.Drop() // texture coordinates are calculated from vertices
+
+	renderer.vao = NewVAO()
+	renderer.vao.Bind()
+	renderer.Shader.EnableVertexAttribArrays()
+	renderer.index.Bind()
+	renderer.vertex.Bind()
+	renderer.vertex.AttribPointer(shader.GetAttribLocation(Default_shader_transition_vertex_attrib), 2, gl.FLOAT, false, 0)
+	renderer.vao.Unbind()
+
+	CheckGLError()
+
+	return renderer
+}
+
+// Drops the GL objects of the transition renderer.
+func (r *TransitionRenderer) Drop() {
+	r.index.Drop()
+	r.vertex.Drop()
+	r.vao.Drop()
+	r.Shader.Drop()
+}
+
+// Draws texture to screen, moved by offset (in screen sizes) and with given alpha.
+func (r *TransitionRenderer) DrawTex(tex *Tex, offset Vec2, alpha float64) {
+	r.draw(tex, offset, alpha, Vec4{}, 0)
+}
+
+// Draws texture to screen, blended with color by amount [0, 1].
+func (r *TransitionRenderer) DrawFade(tex *Tex, color Vec4, amount float64) {
+	r.draw(tex, Vec2{}, 1, color, amount)
+}
+
+func (r *TransitionRenderer) draw(tex *Tex, offset Vec2, alpha float64, color Vec4, amount float64) {
+	r.Shader.Bind()
+	r.Shader.SendUniform1i(Default_shader_transition_tex, 0)
+	r.Shader.SendUniform2f(Default_shader_transition_offset, float32(offset.X), float32(offset.Y))
+	r.Shader.SendUniform1f(Default_shader_transition_alpha, float32(alpha))
+	r.Shader.SendUniform4f(Default_shader_transition_color, float32(color.X), float32(color.Y), float32(color.Z), float32(color.W))
+	r.Shader.SendUniform1f(Default_shader_transition_amount, float32(amount))
+	r.vao.Bind()
+	tex.Bind()
+
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+}
+
+// Fades the outgoing scene to a color, then fades from color to the incoming scene.
+type FadeTransition struct {
+	Color Vec4
+}
+
+// Creates a new fade transition using given color.
+func NewFadeTransition(color Vec4) *FadeTransition {
+	return &FadeTransition{color}
+}
+
+func (t *FadeTransition) Draw(renderer *TransitionRenderer, from, to *Tex, progress float64) {
+	if progress < 0.5 {
+		renderer.DrawFade(from, t.Color, progress*2)
+	} else {
+		renderer.DrawFade(to, t.Color, (1-progress)*2)
+	}
+}
+
+// Blends the incoming scene over the outgoing scene.
+type CrossfadeTransition struct{}
+
+// Creates a new crossfade transition.
+func NewCrossfadeTransition() *CrossfadeTransition {
+	return &CrossfadeTransition{}
+}
+
+func (t *CrossfadeTransition) Draw(renderer *TransitionRenderer, from, to *Tex, progress float64) {
+	renderer.DrawTex(from, Vec2{}, 1)
+	renderer.DrawTex(to, Vec2{}, progress)
+}
+
+// Slides the outgoing scene out of the screen in given direction,
+// while the incoming scene slides in from the opposite side.
+// The direction is given in screen sizes, so {-1, 0} slides to the left.
+type SlideTransition struct {
+	Direction Vec2
+}
+
+// Creates a new slide transition in given direction.
+func NewSlideTransition(direction Vec2) *SlideTransition {
+	return &SlideTransition{direction}
+}
+
+func (t *SlideTransition) Draw(renderer *TransitionRenderer, from, to *Tex, progress float64) {
+	renderer.DrawTex(from, Vec2{t.Direction.X * progress, t.Direction.Y * progress}, 1)
+	renderer.DrawTex(to, Vec2{t.Direction.X * (progress - 1), t.Direction.Y * (progress - 1)}, 1)
+}
+
+// Switches to given scene using a transition running for duration (in seconds).
+// The outgoing scene is paused immediately, but keeps being rendered until the transition has finished.
+// Global render systems (like the default sprite renderer) are drawn into both scenes,
+// so actors of the outgoing scenes keep being visible. The render targets are recreated when the viewport changes.
+// When running headless, the scene is switched without transition.
+func SwitchSceneWithTransition(scene Scene, transition Transition, duration float64) {
+	defaultEngine.SwitchSceneWithTransition(scene, transition, duration)
+}
+
+// Switches to given scene using a transition running for duration (in seconds).
+// See SwitchSceneWithTransition() for details.
+func (e *Engine) SwitchSceneWithTransition(scene Scene, transition Transition, duration float64) {
+	from := e.GetSceneStack()
+	e.SwitchScene(scene)
+
+	if e.headless || transition == nil || duration <= 0 {
+		return
+	}
+
+	e.endTransition()
+
+	if e.transitionRenderer == nil {
+		e.transitionRenderer = NewTransitionRenderer()
+	}
+
+	e.transition = &transitionState{transition: transition, from: from, duration: duration}
+	e.createTransitionTargets()
+	log.Print("Started transition to scene: " + scene.GetName())
+}
+
+// Returns true if a scene transition is running.
+func IsTransitionRunning() bool {
+	return defaultEngine.IsTransitionRunning()
+}
+
+// Returns true if a scene transition is running.
+func (e *Engine) IsTransitionRunning() bool {
+	return e.transition != nil
+}
+
+// Renders outgoing and incoming scenes to textures and draws the transition.
+func (e *Engine) renderTransition(delta float64) {
+	state := e.transition
+	state.time += delta
+	progress := math.Min(state.time/state.duration, 1)
+
+	// the outgoing scene is paused, so it is rendered without time passing
+	state.fromFbo.Bind()
+	e.clear()
+	e.renderScenes(state.from, 0)
+	state.fromFbo.Unbind()
+
+	state.toFbo.Bind()
+	e.clear()
+	e.renderScenes(e.sceneStack, delta)
+	state.toFbo.Unbind()
+
+	e.clear()
+	state.transition.Draw(e.transitionRenderer, state.fromTex, state.toTex, progress)
+
+	if progress >= 1 {
+		e.endTransition()
+	}
+}
+
+// Stops the running transition (if any) and drops its render targets.
+func (e *Engine) endTransition() {
+	if e.transition == nil {
+		return
+	}
+
+	e.dropTransitionTargets()
+	e.transition = nil
+}
+
+// Recreates the render targets of the running transition (if any) to match the viewport size.
+func (e *Engine) resizeTransition() {
+	if e.transition == nil {
+		return
+	}
+
+	e.dropTransitionTargets()
+	e.createTransitionTargets()
+}
+
+// Creates the render targets of the running transition in viewport size.
+func (e *Engine) createTransitionTargets() {
+	state := e.transition
+	state.fromFbo, state.fromTex, state.fromDepth = NewFBOWithTex2DAndDepth(int32(e.viewportWidth), int32(e.viewportHeight), gl.LINEAR)
+	state.toFbo, state.toTex, state.toDepth = NewFBOWithTex2DAndDepth(int32(e.viewportWidth), int32(e.viewportHeight), gl.LINEAR)
+}
+
+func (e *Engine) dropTransitionTargets() {
+	state := e.transition
+	state.fromFbo.Drop()
+	state.fromTex.Drop()
+	state.fromDepth.Drop()
+	state.toFbo.Drop()
+	state.toTex.Drop()
+	state.toDepth.Drop()
+}
@@ -0,0 +1,235 @@
+package goga
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// A file system mounted to the virtual file system.
+// The closer is set for file systems opened by the engine, like zip archives.
+type mount struct {
+	fsys   fs.FS
+	closer io.Closer
+}
+
+// Mounts a file system to the virtual file system used to load resources.
+// Files are looked up in all mounted file systems, later mounts override files of earlier mounts.
+// Files not found in any mount are opened from the OS file system (relative to the working directory).
+// Use this to load resources embedded into the binary using embed.FS.
+func MountFS(fsys fs.FS) {
+	defaultEngine.MountFS(fsys)
+}
+
+// Mounts a file system to the virtual file system used to load resources.
+// See MountFS() for details.
+func (e *Engine) MountFS(fsys fs.FS) {
+	e.mounts = append(e.mounts, mount{fsys, nil})
+	log.Print("Mounted file system")
+}
+
+// Mounts an OS directory to the virtual file system.
+// Paths are resolved relative to the directory.
+// See MountFS() for details.
+func MountDir(dir string) error {
+	return defaultEngine.MountDir(dir)
+}
+
+// Mounts an OS directory to the virtual file system.
+// See MountDir() for details.
+func (e *Engine) MountDir(dir string) error {
+	info, err := os.Stat(dir)
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.New("Cannot mount " + dir + ", not a directory")
+	}
+
+	e.mounts = append(e.mounts, mount{os.DirFS(dir), nil})
+	log.Print("Mounted directory: " + dir)
+
+	return nil
+}
+
+// Mounts a zip archive to the virtual file system.
+// Paths are resolved relative to the root of the archive.
+// The archive is kept open until it is unmounted.
+// See MountFS() for details.
+func MountZip(file string) error {
+	return defaultEngine.MountZip(file)
+}
+
+// Mounts a zip archive to the virtual file system.
+// See MountZip() for details.
+func (e *Engine) MountZip(file string) error {
+	archive, err := zip.OpenReader(file)
+
+	if err != nil {
+		return err
+	}
+
+	e.mounts = append(e.mounts, mount{archive, archive})
+	log.Print("Mounted zip archive: " + file)
+
+	return nil
+}
+
+// Removes all mounts from the virtual file system and closes mounted archives.
+func UnmountAll() {
+	defaultEngine.UnmountAll()
+}
+
+// Removes all mounts from the virtual file system and closes mounted archives.
+func (e *Engine) UnmountAll() {
+	for _, m := range e.mounts {
+		if m.closer != nil {
+			m.closer.Close()
+		}
+	}
+
+	e.mounts = make([]mount, 0)
+	log.Print("Cleared mounts")
+}
+
+// Opens a file from the virtual file system.
+// The file must be closed by the caller.
+func OpenFile(name string) (fs.File, error) {
+	return defaultEngine.OpenFile(name)
+}
+
+// Opens a file from the virtual file system.
+// See OpenFile() for details.
+func (e *Engine) OpenFile(name string) (fs.File, error) {
+	return openFile(e.mounts, name)
+}
+
+// Opens a file from given mounts, or the OS file system if not found.
+func openFile(mounts []mount, name string) (fs.File, error) {
+	if vfsName, ok := vfsPath(name); ok {
+		for i := len(mounts) - 1; i >= 0; i-- {
+			file, err := mounts[i].fsys.Open(vfsName)
+
+			if err == nil {
+				return file, nil
+			}
+
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	return os.Open(name)
+}
+
+// Returns the file info of a file from the virtual file system.
+func StatFile(name string) (fs.FileInfo, error) {
+	return defaultEngine.StatFile(name)
+}
+
+// Returns the file info of a file from the virtual file system.
+// See OpenFile() for details.
+func (e *Engine) StatFile(name string) (fs.FileInfo, error) {
+	if vfsName, ok := vfsPath(name); ok {
+		for i := len(e.mounts) - 1; i >= 0; i-- {
+			info, err := fs.Stat(e.mounts[i].fsys, vfsName)
+
+			if err == nil {
+				return info, nil
+			}
+
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	return os.Stat(name)
+}
+
+// Reads a file from the virtual file system.
+func ReadFile(name string) ([]byte, error) {
+	return defaultEngine.ReadFile(name)
+}
+
+// Reads a file from the virtual file system.
+func (e *Engine) ReadFile(name string) ([]byte, error) {
+	file, err := e.OpenFile(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// Reads a directory from the virtual file system, sorted by file name.
+// The entries of the directory in all mounts are merged.
+// If no mount contains the directory, it is read from the OS file system.
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return defaultEngine.ReadDir(name)
+}
+
+// Reads a directory from the virtual file system, sorted by file name.
+// See ReadDir() for details.
+func (e *Engine) ReadDir(name string) ([]fs.DirEntry, error) {
+	vfsName, ok := vfsPath(name)
+
+	if !ok {
+		return os.ReadDir(name)
+	}
+
+	entries := make(map[string]fs.DirEntry)
+	found := false
+
+	for _, m := range e.mounts {
+		list, err := fs.ReadDir(m.fsys, vfsName)
+
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		found = true
+
+		for _, entry := range list {
+			entries[entry.Name()] = entry
+		}
+	}
+
+	if !found {
+		return os.ReadDir(name)
+	}
+
+	list := make([]fs.DirEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+
+	return list, nil
+}
+
+// Converts a file path to a path within mounted file systems.
+// Returns false if the path cannot be resolved within mounts, like absolute paths.
+func vfsPath(name string) (string, bool) {
+	name = path.Clean(filepath.ToSlash(name))
+
+	return name, fs.ValidPath(name)
+}
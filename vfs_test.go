@@ -0,0 +1,179 @@
+package goga
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// Creates a zip archive containing given files and returns its path.
+func createTestZip(t *testing.T, files map[string]string) string {
+	name := filepath.Join(t.TempDir(), "test.zip")
+	file, err := os.Create(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+	archive := zip.NewWriter(file)
+
+	for path, content := range files {
+		w, err := archive.Create(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write([]byte(content))
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return name
+}
+
+// Creates an OS directory containing given files and returns its path.
+func createTestDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for path, content := range files {
+		name := filepath.Join(dir, filepath.FromSlash(path))
+
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMountOverride(t *testing.T) {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("fs")}, "b.txt": &fstest.MapFile{Data: []byte("fs")}})
+
+	if err := e.MountDir(createTestDir(t, map[string]string{"a.txt": "dir", "c.txt": "dir"})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.MountZip(createTestZip(t, map[string]string{"c.txt": "zip"})); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{"a.txt": "dir", "b.txt": "fs", "c.txt": "zip"} {
+		data, err := e.ReadFile(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != expected {
+			t.Fatalf("Expected %v to be read from %v, got %v", name, expected, string(data))
+		}
+	}
+
+	if info, err := e.StatFile("c.txt"); err != nil || info.Size() != 3 {
+		t.Fatalf("Expected file info of latest mount, got %v", err)
+	}
+
+	e.UnmountAll()
+
+	if _, err := e.ReadFile("a.txt"); err == nil {
+		t.Fatal("Expected files to be gone after unmounting")
+	}
+}
+
+func TestMountErrors(t *testing.T) {
+	e := NewEngine()
+
+	if err := e.MountDir("missing"); err == nil {
+		t.Fatal("Expected error for missing directory")
+	}
+
+	if err := e.MountDir("vfs.go"); err == nil {
+		t.Fatal("Expected error for file mounted as directory")
+	}
+
+	if err := e.MountZip("vfs.go"); err == nil {
+		t.Fatal("Expected error for invalid zip archive")
+	}
+
+	if len(e.mounts) != 0 {
+		t.Fatal("Expected nothing to be mounted")
+	}
+}
+
+func TestOSFallback(t *testing.T) {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}})
+
+	// relative to the working directory, which is the package directory when testing
+	if data, err := e.ReadFile("vfs_test.go"); err != nil || len(data) == 0 {
+		t.Fatalf("Expected file not found in mounts to be read from OS, got %v", err)
+	}
+
+	dir := createTestDir(t, map[string]string{"b.txt": "b", "sub/c.txt": "c"})
+
+	if data, err := e.ReadFile(filepath.Join(dir, "b.txt")); err != nil || string(data) != "b" {
+		t.Fatalf("Expected absolute path to be read from OS, got %v", err)
+	}
+
+	if entries, err := e.ReadDir(dir); err != nil || len(entries) != 2 {
+		t.Fatalf("Expected absolute directory to be read from OS, got %v", err)
+	}
+
+	if _, err := e.ReadFile("missing.txt"); !os.IsNotExist(err) {
+		t.Fatalf("Expected missing file error, got %v", err)
+	}
+}
+
+func TestReadDirMergesMounts(t *testing.T) {
+	e := NewEngine()
+	e.MountFS(fstest.MapFS{
+		"res/a.txt":     &fstest.MapFile{Data: []byte("fs")},
+		"res/fs/b.txt":  &fstest.MapFile{Data: []byte("fs")},
+		"other/end.txt": &fstest.MapFile{Data: []byte("fs")},
+	})
+
+	if err := e.MountDir(createTestDir(t, map[string]string{"res/a.txt": "dir", "res/dir.txt": "dir"})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.MountZip(createTestZip(t, map[string]string{"res/zip.txt": "zip", "res/zip/c.txt": "zip"})); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := e.ReadDir("res")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if !equalStrings(names, []string{"a.txt", "dir.txt", "fs", "zip", "zip.txt"}) {
+		t.Fatalf("Expected entries of all mounts sorted by name, got %v", names)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() != (entry.Name() == "fs" || entry.Name() == "zip") {
+			t.Fatalf("Expected %v to be reported as directory: %v", entry.Name(), entry.IsDir())
+		}
+	}
+
+	if _, err := e.ReadDir("missing"); !os.IsNotExist(err) {
+		t.Fatalf("Expected missing directory error, got %v", err)
+	}
+}